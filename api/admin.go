@@ -0,0 +1,123 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate/tenant"
+)
+
+// errorResponse is a response structure for failed admin requests.
+type errorResponse struct {
+	Error *apiError `json:"error"`
+}
+
+// AdminHandler returns a handler for admin routes that manage tenants' private-label issuers:
+//
+//	GET    /admin/tenants/{tenant}/issuers
+//	PUT    /admin/tenants/{tenant}/issuers/{name}
+//	DELETE /admin/tenants/{tenant}/issuers/{name}
+//
+// All routes require cfg.AdminToken as a bearer token.
+func AdminHandler(cfg Config) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /admin/tenants/{tenant}/issuers", adminRoute(cfg, handleListIssuers))
+	mux.Handle("PUT /admin/tenants/{tenant}/issuers/{name}", adminRoute(cfg, handlePutIssuer))
+	mux.Handle("DELETE /admin/tenants/{tenant}/issuers/{name}", adminRoute(cfg, handleDeleteIssuer))
+	return mux
+}
+
+// adminRoute wraps an admin handler with authorization and error rendering.
+func adminRoute(cfg Config, h func(Config, http.ResponseWriter, *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := authorizeAdmin(cfg, r)
+		if err == nil {
+			err = h(cfg, w, r)
+		}
+		if err == nil {
+			return
+		}
+
+		res := asAPIError(err)
+		renderJSON(w, res.StatusCode, errorResponse{Error: res})
+	})
+}
+
+// authorizeAdmin checks that r carries the admin bearer token.
+func authorizeAdmin(cfg Config, r *http.Request) error {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || cfg.AdminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(cfg.AdminToken)) != 1 {
+		return &apiError{
+			StatusCode: http.StatusUnauthorized,
+			Code:       errUnauthorized,
+			Message:    "Unauthorized",
+		}
+	}
+	if cfg.Tenants == nil {
+		return &apiError{
+			StatusCode: http.StatusNotFound,
+			Code:       errNotFound,
+			Message:    "Tenant store is not configured",
+		}
+	}
+	return nil
+}
+
+// issuerList is a response structure for listing tenant's issuers.
+type issuerList struct {
+	Issuers []tenant.Issuer `json:"issuers"`
+}
+
+// handleListIssuers lists private-label issuers registered by a tenant.
+func handleListIssuers(cfg Config, w http.ResponseWriter, r *http.Request) error {
+	issuers := cfg.Tenants.Issuers(r.PathValue("tenant"))
+	if issuers == nil {
+		issuers = []tenant.Issuer{}
+	}
+	return renderJSON(w, http.StatusOK, issuerList{Issuers: issuers})
+}
+
+// handlePutIssuer registers or replaces a tenant's private-label issuer.
+func handlePutIssuer(cfg Config, w http.ResponseWriter, r *http.Request) error {
+	iss, err := decodeJSON[tenant.Issuer](r)
+	if err != nil {
+		return &apiError{
+			StatusCode: http.StatusBadRequest,
+			Code:       errGeneralError,
+			Message:    "Invalid JSON request",
+		}
+	}
+	iss.Name = r.PathValue("name")
+
+	if err := cfg.Tenants.Put(r.PathValue("tenant"), *iss); err != nil {
+		if errors.Is(err, tenant.ErrInvalidTenant) || errors.Is(err, tenant.ErrInvalidIssuer) {
+			return &apiError{
+				StatusCode: http.StatusBadRequest,
+				Code:       errGeneralError,
+				Message:    err.Error(),
+				OrigError:  err,
+			}
+		}
+		return err
+	}
+	return renderJSON(w, http.StatusOK, iss)
+}
+
+// handleDeleteIssuer removes a tenant's private-label issuer.
+func handleDeleteIssuer(cfg Config, w http.ResponseWriter, r *http.Request) error {
+	if err := cfg.Tenants.Delete(r.PathValue("tenant"), r.PathValue("name")); err != nil {
+		if errors.Is(err, tenant.ErrNotFound) {
+			return &apiError{
+				StatusCode: http.StatusNotFound,
+				Code:       errNotFound,
+				Message:    "Issuer not found",
+				OrigError:  err,
+			}
+		}
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
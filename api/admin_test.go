@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate/issuer"
+	"github.com/waterfountain1996/cardvalidate/tenant"
+)
+
+func newTestConfig(t *testing.T) Config {
+	store, err := tenant.Open("")
+	if err != nil {
+		t.Fatalf("error opening tenant store: %s", err)
+	}
+	return Config{Tenants: store, AdminToken: "secret"}
+}
+
+func TestAdminHandler_Unauthorized(t *testing.T) {
+	handler := AdminHandler(newTestConfig(t))
+
+	for _, token := range []string{"", "Bearer wrong"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/admin/tenants/acme/issuers", nil)
+		req.Header.Set("Authorization", token)
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("unexpected status code: want %d have %d", http.StatusUnauthorized, rec.Code)
+		}
+	}
+}
+
+func TestAdminHandler_TenantIssuers(t *testing.T) {
+	cfg := newTestConfig(t)
+	admin := AdminHandler(cfg)
+	validation := ValidationHandler(cfg)
+
+	validate := func(tenantID string) validationResponse {
+		rec := httptest.NewRecorder()
+		req := newJSONRequest(t, "POST", "/validate", cardRequest{
+			CardNumber:     "7005123412341234",
+			ExpirationDate: anyFutureDate(),
+		})
+		req.Header.Set(tenantHeader, tenantID)
+		validation.ServeHTTP(rec, req)
+
+		var body validationResponse
+		if err := json.NewDecoder(rec.Result().Body).Decode(&body); err != nil {
+			t.Fatalf("error parsing JSON response: %s", err)
+		}
+		return body
+	}
+
+	rec := httptest.NewRecorder()
+	req := newJSONRequest(t, "PUT", "/admin/tenants/acme/issuers/Acme%20Fleet", tenant.Issuer{
+		Ranges:   []issuer.IntRange{issuer.NewIntRange(7000, 7009)},
+		Length:   issuer.NewSingleIntRange(16),
+		Checksum: "none",
+	})
+	req.Header.Set("Authorization", "Bearer secret")
+	admin.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: want %d have %d", http.StatusOK, rec.Code)
+	}
+
+	if body := validate("acme"); !body.Valid || body.Issuer != "Acme Fleet" {
+		t.Fatalf("expected card to be valid for tenant: %+v", body)
+	}
+	if body := validate("other"); body.Valid || body.Error.Code != errUnknownIssuer {
+		t.Fatalf("expected unknown issuer for another tenant: %+v", body)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("DELETE", "/admin/tenants/acme/issuers/Acme%20Fleet", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	admin.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code: want %d have %d", http.StatusNoContent, rec.Code)
+	}
+
+	if body := validate("acme"); body.Valid {
+		t.Fatalf("expected card to be invalid after issuer removal")
+	}
+}
+
+func TestAdminHandler_InvalidIssuer(t *testing.T) {
+	handler := AdminHandler(newTestConfig(t))
+
+	rec := httptest.NewRecorder()
+	req := newJSONRequest(t, "PUT", "/admin/tenants/acme/issuers/Broken", tenant.Issuer{
+		Checksum: "luhn",
+	})
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: want %d have %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -5,10 +5,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"time"
 
 	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/fingerprint"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+	"github.com/waterfountain1996/cardvalidate/jwe"
+	"github.com/waterfountain1996/cardvalidate/tenant"
+	"github.com/waterfountain1996/cardvalidate/vault"
 )
 
 // Application error code.
@@ -21,6 +29,10 @@ const (
 	errInvalidAccountNumber
 	errMalformedDate
 	errCardExpired
+	errUnauthorized
+	errNotFound
+	errTestCard
+	errSuspiciousNumber
 )
 
 // apiError represents an HTTP API error returned from handlers.
@@ -29,6 +41,9 @@ type apiError struct {
 	Message    string       `json:"message,omitempty"`
 	StatusCode int          `json:"-"`
 	OrigError  error        `json:"-"`
+
+	// Validation result that produced a validation error.
+	result cardvalidate.Result
 }
 
 // Error implements error.
@@ -36,72 +51,224 @@ func (e *apiError) Error() string {
 	return e.Message
 }
 
+// joseContentType is the media type of request payloads encrypted as compact JWE.
+const joseContentType = "application/jose"
+
+// maxJWESize limits the size of encrypted request payloads.
+const maxJWESize = 64 << 10
+
+// tenantHeader is a request header that selects tenant's private-label issuers for validation.
+const tenantHeader = "X-Tenant-ID"
+
+// now returns the current time, it's replaced in tests.
+var now = time.Now
+
+// Config holds dependencies shared by API handlers.
+type Config struct {
+	Tenants      *tenant.Store             // Private-label issuers registered per tenant, optional.
+	AdminToken   string                    // Bearer token required by admin routes.
+	Lenient      bool                      // Accept unknown IINs with a warning.
+	TestCardMode cardvalidate.TestCardMode // How well-known test card numbers are treated.
+	PatternMode  cardvalidate.PatternMode  // How synthetic-looking card numbers are treated.
+	Fingerprints *fingerprint.Keyring      // Keys for card number fingerprints, optional.
+	Vault        *vault.Vault              // Token vault, optional.
+	VaultClients map[string][]Scope        // Vault clients' scopes by bearer token.
+	JWEKeys      *jwe.KeySet               // Keys for encrypted request payloads, optional.
+	BINDatabase  *issuer.BINDatabase       // Card product details by BIN, optional.
+}
+
 // ValidationHandler returns a handler that validates credit card information.
-func ValidationHandler() http.Handler {
+func ValidationHandler(cfg Config) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		err := handleCardValidation(w, r)
+		err := handleCardValidation(cfg, w, r)
 		if err == nil {
 			return
 		}
 
-		var res *apiError
-		if !errors.As(err, &res) {
-			// If handler returns an unwrapped error we can assume it's an internal server error.
-			log.Printf("unhandled error: %s\n", err)
-			res = &apiError{
-				StatusCode: http.StatusInternalServerError,
-				Message:    "Internal server error",
-			}
-		}
-
-		renderJSON(w, res.StatusCode, validationResponse{Error: res})
+		res := asAPIError(err)
+		renderJSON(w, res.StatusCode, validationResponse{
+			RegistryVersion: res.result.RegistryVersion,
+			Warnings:        res.result.Warnings(),
+			Findings:        res.result.Findings,
+			Error:           res,
+		})
 	})
 }
 
+// asAPIError converts err returned from a handler into an apiError.
+func asAPIError(err error) *apiError {
+	var res *apiError
+	if !errors.As(err, &res) {
+		// If handler returns an unwrapped error we can assume it's an internal server error.
+		log.Printf("unhandled error: %s\n", err)
+		res = &apiError{
+			StatusCode: http.StatusInternalServerError,
+			Message:    "Internal server error",
+		}
+	}
+	return res
+}
+
 // handleCardValidation handles credit card validation for given request.
-func handleCardValidation(w http.ResponseWriter, r *http.Request) error {
-	ccInfo, err := decodeJSON[creditCardInfo](r)
+func handleCardValidation(cfg Config, w http.ResponseWriter, r *http.Request) error {
+	ccInfo, err := decodeCardInfo(cfg, r)
 	if err != nil {
-		return &apiError{
-			StatusCode: http.StatusBadRequest,
+		return err
+	}
+	defer ccInfo.CardNumber.Zero()
+
+	v := newValidator(cfg, r)
+
+	charges := []time.Time{now().UTC()}
+	if ccInfo.ValidThrough != "" {
+		t, err := time.Parse(time.DateOnly, ccInfo.ValidThrough)
+		if err != nil {
+			return &apiError{
+				StatusCode: http.StatusBadRequest,
+				Code:       errGeneralError,
+				Message:    "Malformed valid_through date",
+			}
+		}
+		charges = append(charges, t)
+	}
+
+	result, err := v.ValidatePANSchedule(ccInfo.CardNumber, ccInfo.ExpirationDate, charges)
+	if err != nil {
+		return validationError(err, result, charges)
+	}
+
+	resp := validationResponse{
+		Valid:           true,
+		Issuer:          result.IssuerName,
+		Checksum:        result.Checksum.Name(),
+		RegistryVersion: result.RegistryVersion,
+		Warnings:        result.Warnings(),
+		Findings:        result.Findings,
+	}
+	if result.BIN != (issuer.BINInfo{}) {
+		resp.BIN = &result.BIN
+	}
+	if ccInfo.Fingerprint && cfg.Fingerprints != nil {
+		fp := cfg.Fingerprints.Fingerprint(ccInfo.CardNumber)
+		resp.Fingerprint, resp.FingerprintKeyID = fp.Value, fp.KeyID
+	}
+	return renderJSON(w, http.StatusOK, resp)
+}
+
+// decodeCardInfo decodes a card request payload, which is either plain JSON or, with
+// the application/jose content type, JSON encrypted as a compact JWE.
+func decodeCardInfo(cfg Config, r *http.Request) (*creditCardInfo, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != joseContentType {
+		ccInfo, err := decodeJSON[creditCardInfo](r)
+		if err != nil {
+			return nil, &apiError{
+				StatusCode: http.StatusBadRequest,
+				Code:       errGeneralError,
+				Message:    "Invalid JSON request",
+			}
+		}
+		return ccInfo, nil
+	}
+
+	if cfg.JWEKeys == nil {
+		return nil, &apiError{
+			StatusCode: http.StatusUnsupportedMediaType,
 			Code:       errGeneralError,
-			Message:    "Invalid JSON request",
+			Message:    "Encrypted payloads are not enabled",
 		}
 	}
 
-	if err := cardvalidate.Validate(ccInfo.CardNumber, ccInfo.ExpirationDate); err != nil {
-		e := &apiError{
-			StatusCode: http.StatusUnprocessableEntity,
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxJWESize))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := jwe.Decrypt(cfg.JWEKeys, string(body))
+	if err != nil {
+		return nil, &apiError{
+			StatusCode: http.StatusBadRequest,
+			Code:       errGeneralError,
+			Message:    "Invalid encrypted payload",
 			OrigError:  err,
 		}
-		switch {
-		case errors.Is(err, cardvalidate.ErrMalformedNumber):
-			e.Code, e.Message = errMalformedNumber, "Malformed credit card number"
-		case errors.Is(err, cardvalidate.ErrUnknownIssuer):
-			e.Code, e.Message = errUnknownIssuer, "Unknown IIN"
-		case errors.Is(err, cardvalidate.ErrInvalidAccountNumber):
-			e.Code, e.Message = errInvalidAccountNumber, "Invalid account number"
-		case errors.Is(err, cardvalidate.ErrMalformedDate):
-			e.Code, e.Message = errMalformedDate, "Malformed expiration date"
-		case errors.Is(err, cardvalidate.ErrCardExpired):
-			e.Code, e.Message = errCardExpired, "Credit card has expired"
+	}
+	defer clear(plaintext)
+
+	ccInfo := new(creditCardInfo)
+	if err := json.Unmarshal(plaintext, ccInfo); err != nil {
+		return nil, &apiError{
+			StatusCode: http.StatusBadRequest,
+			Code:       errGeneralError,
+			Message:    "Invalid JSON request",
 		}
-		return e
 	}
+	return ccInfo, nil
+}
 
-	return renderJSON(w, http.StatusOK, validationResponse{Valid: true})
+// newValidator returns a validator configured by cfg and the tenant r is made on behalf of.
+func newValidator(cfg Config, r *http.Request) cardvalidate.Validator {
+	v := cardvalidate.Validator{
+		Lenient:      cfg.Lenient,
+		TestCardMode: cfg.TestCardMode,
+		PatternMode:  cfg.PatternMode,
+		BINDatabase:  cfg.BINDatabase,
+	}
+	if id := r.Header.Get(tenantHeader); id != "" && cfg.Tenants != nil {
+		v.Registry = cfg.Tenants.Registry(id)
+	}
+	return v
+}
+
+// validationError converts a validation error into an apiError. charges are the dates the card
+// was validated for, the second one being the valid_through date if it was set.
+func validationError(err error, result cardvalidate.Result, charges []time.Time) *apiError {
+	e := &apiError{
+		StatusCode: http.StatusUnprocessableEntity,
+		OrigError:  err,
+		result:     result,
+	}
+	var chargeErr *cardvalidate.ChargeError
+	switch {
+	case errors.Is(err, cardvalidate.ErrMalformedNumber):
+		e.Code, e.Message = errMalformedNumber, "Malformed credit card number"
+	case errors.Is(err, cardvalidate.ErrUnknownIssuer):
+		e.Code, e.Message = errUnknownIssuer, "Unknown IIN"
+	case errors.Is(err, cardvalidate.ErrInvalidAccountNumber):
+		e.Code, e.Message = errInvalidAccountNumber, "Invalid account number"
+	case errors.Is(err, cardvalidate.ErrMalformedDate):
+		e.Code, e.Message = errMalformedDate, "Malformed expiration date"
+	case errors.As(err, &chargeErr) && len(charges) > 1 && chargeErr.Date.Equal(charges[1]):
+		e.Code, e.Message = errCardExpired, "Credit card expires before valid_through date"
+	case errors.Is(err, cardvalidate.ErrCardExpired):
+		e.Code, e.Message = errCardExpired, "Credit card has expired"
+	case errors.Is(err, cardvalidate.ErrTestCard):
+		e.Code, e.Message = errTestCard, "Test card numbers are not accepted"
+	case errors.Is(err, cardvalidate.ErrSuspiciousNumber):
+		e.Code, e.Message = errSuspiciousNumber, "Suspicious card number"
+	}
+	return e
 }
 
 // creditCardInfo is a request payload for validation handler.
 type creditCardInfo struct {
-	CardNumber     string `json:"number"`
-	ExpirationDate string `json:"exp_date"`
+	CardNumber     cardvalidate.PAN `json:"number"`
+	ExpirationDate string           `json:"exp_date"`
+	ValidThrough   string           `json:"valid_through,omitempty"` // Date the card has to stay valid through.
+	Fingerprint    bool             `json:"fingerprint,omitempty"`   // Include card number's fingerprint in the response.
 }
 
 // validationResponse is a response structure for validation handler.
 type validationResponse struct {
-	Valid bool      `json:"valid"`
-	Error *apiError `json:"error,omitempty"`
+	Valid            bool                   `json:"valid"`
+	Issuer           string                 `json:"issuer,omitempty"`
+	Checksum         string                 `json:"checksum,omitempty"`
+	RegistryVersion  string                 `json:"registry_version,omitempty"`
+	BIN              *issuer.BINInfo        `json:"bin,omitempty"`      // BIN database record, if there is one.
+	Warnings         []cardvalidate.Finding `json:"warnings,omitempty"` // Findings of warning severity and above.
+	Findings         []cardvalidate.Finding `json:"findings,omitempty"` // All findings, including info ones.
+	Fingerprint      string                 `json:"fingerprint,omitempty"`
+	FingerprintKeyID string                 `json:"fingerprint_key_id,omitempty"`
+	Error            *apiError              `json:"error,omitempty"`
 }
 
 // decodeJSON unmarshals JSON request body into T.
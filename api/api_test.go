@@ -10,8 +10,21 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/fingerprint"
+	"github.com/waterfountain1996/cardvalidate/issuer"
 )
 
+// cardRequest is a validation request payload. Unlike creditCardInfo, it encodes the raw
+// card number.
+type cardRequest struct {
+	CardNumber     string `json:"number"`
+	ExpirationDate string `json:"exp_date"`
+	ValidThrough   string `json:"valid_through,omitempty"`
+	Fingerprint    bool   `json:"fingerprint,omitempty"`
+}
+
 func newJSONRequest(t *testing.T, method, target string, body any) *http.Request {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(body); err != nil {
@@ -31,7 +44,7 @@ func anyPastDate() string {
 }
 
 func TestValidationHandler_InvalidJSON(t *testing.T) {
-	handler := ValidationHandler()
+	handler := ValidationHandler(Config{})
 
 	rec := httptest.NewRecorder()
 	req := httptest.NewRequest("POST", "/validate", strings.NewReader("foobarbaz"))
@@ -97,11 +110,11 @@ func TestValidationHandler_CardValidation(t *testing.T) {
 		{"6212345678911036", anyFutureDate(), errInvalidAccountNumber},
 	}
 
-	handler := ValidationHandler()
+	handler := ValidationHandler(Config{})
 
 	for _, tc := range tests {
 		rec := httptest.NewRecorder()
-		req := newJSONRequest(t, "POST", "/validate", creditCardInfo{
+		req := newJSONRequest(t, "POST", "/validate", cardRequest{
 			CardNumber:     tc.number,
 			ExpirationDate: tc.expDate,
 		})
@@ -126,6 +139,11 @@ func TestValidationHandler_CardValidation(t *testing.T) {
 		if body.Error.Code != tc.code {
 			t.Fatalf("API error code mismatch: want %d have %d", tc.code, body.Error.Code)
 		}
+
+		if body.RegistryVersion != issuer.Version() {
+			t.Fatalf("registry version mismatch: want %s have %s",
+				issuer.Version(), body.RegistryVersion)
+		}
 	}
 }
 
@@ -213,12 +231,12 @@ func TestValidationHandler(t *testing.T) {
 		{"4539984459069503", anyFutureDate()},
 	}
 
-	handler := ValidationHandler()
+	handler := ValidationHandler(Config{})
 
 	for _, tc := range tests {
 		t.Run(fmt.Sprintf("%s %s", tc.number, tc.expDate), func(t *testing.T) {
 			rec := httptest.NewRecorder()
-			req := newJSONRequest(t, "POST", "/validate", creditCardInfo{
+			req := newJSONRequest(t, "POST", "/validate", cardRequest{
 				CardNumber:     tc.number,
 				ExpirationDate: tc.expDate,
 			})
@@ -239,6 +257,253 @@ func TestValidationHandler(t *testing.T) {
 			if !body.Valid {
 				t.Fatalf("expected 'valid' to be true, got false (%+v)", body.Error)
 			}
+
+			if body.Checksum == "" {
+				t.Fatalf("expected 'checksum' to be set")
+			}
 		})
 	}
 }
+
+func TestValidationHandler_Lenient(t *testing.T) {
+	handler := ValidationHandler(Config{Lenient: true})
+
+	rec := httptest.NewRecorder()
+	req := newJSONRequest(t, "POST", "/validate", cardRequest{
+		CardNumber:     "9111111111111110",
+		ExpirationDate: anyFutureDate(),
+	})
+
+	handler.ServeHTTP(rec, req)
+	res := rec.Result()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: want %d have %s", http.StatusOK, res.Status)
+	}
+
+	var body struct {
+		Valid    bool   `json:"valid"`
+		Issuer   string `json:"issuer"`
+		Warnings []struct {
+			Code     string `json:"code"`
+			Severity string `json:"severity"`
+		} `json:"warnings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+
+	if !body.Valid || body.Issuer != "unknown" {
+		t.Fatalf("unexpected response: %+v", body)
+	}
+
+	if len(body.Warnings) != 1 || body.Warnings[0].Code != "unknown_iin" || body.Warnings[0].Severity != "warning" {
+		t.Fatalf("unexpected warnings: %+v", body.Warnings)
+	}
+}
+
+func TestValidationHandler_Warnings(t *testing.T) {
+	handler := ValidationHandler(Config{})
+
+	// The card expires on August 1st, 22 days later.
+	now = func() time.Time { return time.Date(2030, time.July, 10, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	rec := httptest.NewRecorder()
+	req := newJSONRequest(t, "POST", "/validate", cardRequest{
+		CardNumber:     "6212345678900000003",
+		ExpirationDate: "08/2030",
+	})
+
+	handler.ServeHTTP(rec, req)
+	res := rec.Result()
+
+	var body validationResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+
+	if !body.Valid {
+		t.Fatalf("expected 'valid' to be true, got false (%+v)", body.Error)
+	}
+
+	if len(body.Warnings) != 1 || body.Warnings[0].Code != cardvalidate.CodeExpiresSoon ||
+		body.Warnings[0].Severity != cardvalidate.SeverityWarning {
+		t.Fatalf("unexpected warnings: %+v", body.Warnings)
+	}
+}
+
+func TestValidationHandler_BINDatabase(t *testing.T) {
+	db, err := issuer.LoadBINDatabase("../issuer/testdata/bins.csv")
+	if err != nil {
+		t.Fatalf("error loading BIN database: %s", err)
+	}
+	handler := ValidationHandler(Config{BINDatabase: db})
+
+	rec := httptest.NewRecorder()
+	req := newJSONRequest(t, "POST", "/validate", cardRequest{
+		CardNumber:     "5555555555554444",
+		ExpirationDate: anyFutureDate(),
+	})
+
+	handler.ServeHTTP(rec, req)
+	res := rec.Result()
+
+	var body validationResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+
+	want := issuer.BINInfo{BIN: "555555", Funding: issuer.FundingCredit, Level: "standard", Bank: "Example Savings", Country: "GB"}
+	if !body.Valid || body.BIN == nil || *body.BIN != want {
+		t.Fatalf("unexpected BIN record: %+v", body.BIN)
+	}
+}
+
+func TestValidationHandler_TestCards(t *testing.T) {
+	handler := ValidationHandler(Config{TestCardMode: cardvalidate.TestCardsReject})
+
+	rec := httptest.NewRecorder()
+	req := newJSONRequest(t, "POST", "/validate", cardRequest{
+		CardNumber:     "4242424242424242",
+		ExpirationDate: anyFutureDate(),
+	})
+
+	handler.ServeHTTP(rec, req)
+	res := rec.Result()
+
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("unexpected status code: want %d have %s",
+			http.StatusUnprocessableEntity, res.Status)
+	}
+
+	var body validationResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+
+	if body.Error.Code != errTestCard {
+		t.Fatalf("API error code mismatch: want %d have %d", errTestCard, body.Error.Code)
+	}
+}
+
+func TestValidationHandler_Patterns(t *testing.T) {
+	tests := []struct {
+		mode   cardvalidate.PatternMode
+		status int
+	}{
+		{cardvalidate.PatternsWarn, http.StatusOK},
+		{cardvalidate.PatternsReject, http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range tests {
+		handler := ValidationHandler(Config{PatternMode: tc.mode})
+
+		rec := httptest.NewRecorder()
+		req := newJSONRequest(t, "POST", "/validate", cardRequest{
+			CardNumber:     "4539721234567892",
+			ExpirationDate: anyFutureDate(),
+		})
+
+		handler.ServeHTTP(rec, req)
+		res := rec.Result()
+
+		if res.StatusCode != tc.status {
+			t.Fatalf("unexpected status code (%s): want %d have %s", tc.mode, tc.status, res.Status)
+		}
+
+		var body validationResponse
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatalf("error parsing JSON response: %s", err)
+		}
+
+		if len(body.Warnings) != 1 || body.Warnings[0].Code != cardvalidate.CodeSequentialDigits {
+			t.Fatalf("unexpected warnings (%s): %+v", tc.mode, body.Warnings)
+		}
+	}
+}
+
+func TestValidationHandler_ValidThrough(t *testing.T) {
+	expDate := time.Now().UTC().AddDate(1, 0, 0)
+
+	tests := []struct {
+		validThrough string
+		status       int
+		code         apiErrorCode
+	}{
+		{expDate.AddDate(0, -2, 0).Format(time.DateOnly), http.StatusOK, 0},
+		{expDate.AddDate(0, 2, 0).Format(time.DateOnly), http.StatusUnprocessableEntity, errCardExpired},
+		{"01/2030", http.StatusBadRequest, errGeneralError},
+	}
+
+	handler := ValidationHandler(Config{})
+
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		req := newJSONRequest(t, "POST", "/validate", cardRequest{
+			CardNumber:     "4539983514929271",
+			ExpirationDate: expDate.Format("01/2006"),
+			ValidThrough:   tc.validThrough,
+		})
+
+		handler.ServeHTTP(rec, req)
+		res := rec.Result()
+
+		if res.StatusCode != tc.status {
+			t.Fatalf("unexpected status code (%s): want %d have %s", tc.validThrough, tc.status, res.Status)
+		}
+
+		var body validationResponse
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatalf("error parsing JSON response: %s", err)
+		}
+
+		if tc.status != http.StatusOK && body.Error.Code != tc.code {
+			t.Fatalf("API error code mismatch: want %d have %d", tc.code, body.Error.Code)
+		}
+	}
+}
+
+func TestValidationHandler_Fingerprint(t *testing.T) {
+	keys, err := fingerprint.NewKeyring("k1", fingerprint.Key{ID: "k1", Secret: bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cfg       Config
+		requested bool
+		want      bool
+	}{
+		{Config{Fingerprints: keys}, true, true},
+		{Config{Fingerprints: keys}, false, false},
+		{Config{}, true, false},
+	}
+
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		req := newJSONRequest(t, "POST", "/validate", cardRequest{
+			CardNumber:     "4539983514929271",
+			ExpirationDate: anyFutureDate(),
+			Fingerprint:    tc.requested,
+		})
+
+		ValidationHandler(tc.cfg).ServeHTTP(rec, req)
+
+		var body validationResponse
+		if err := json.NewDecoder(rec.Result().Body).Decode(&body); err != nil {
+			t.Fatalf("error parsing JSON response: %s", err)
+		}
+
+		if !tc.want {
+			if body.Fingerprint != "" || body.FingerprintKeyID != "" {
+				t.Errorf("unexpected fingerprint: %+v", body)
+			}
+			continue
+		}
+		fp := fingerprint.Fingerprint{KeyID: body.FingerprintKeyID, Value: body.Fingerprint}
+		if !keys.Verify(cardvalidate.NewPAN("4539983514929271"), fp) {
+			t.Errorf("fingerprint doesn't match the card number: %+v", fp)
+		}
+	}
+}
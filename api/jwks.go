@@ -0,0 +1,18 @@
+package api
+
+import "net/http"
+
+// JWKSHandler returns a handler that publishes the public keys clients encrypt request
+// payloads to, in JSON Web Key Set format.
+func JWKSHandler(cfg Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.JWEKeys == nil {
+			renderJSON(w, http.StatusNotFound, errorResponse{Error: &apiError{
+				Code:    errNotFound,
+				Message: "Encrypted payloads are not enabled",
+			}})
+			return
+		}
+		renderJSON(w, http.StatusOK, cfg.JWEKeys.JWKS())
+	})
+}
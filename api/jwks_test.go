@@ -0,0 +1,134 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate/jwe"
+)
+
+// newJWEConfig returns a config with a freshly generated decryption key.
+func newJWEConfig(t *testing.T) (Config, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := jwe.NewKeySet(jwe.Key{ID: "k1", PrivateKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{JWEKeys: keys}, key
+}
+
+// newJWERequest returns a validation request with body encrypted to key.
+func newJWERequest(t *testing.T, key *rsa.PublicKey, kid string, body any) *http.Request {
+	plaintext, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact, err := jwe.Encrypt(key, kid, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/validate", strings.NewReader(compact))
+	req.Header.Set("Content-Type", "application/jose")
+	return req
+}
+
+func TestValidationHandler_JWE(t *testing.T) {
+	cfg, key := newJWEConfig(t)
+	handler := ValidationHandler(cfg)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newJWERequest(t, &key.PublicKey, "k1", cardRequest{
+		CardNumber:     "4539983514929271",
+		ExpirationDate: anyFutureDate(),
+	}))
+	res := rec.Result()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %s", res.Status)
+	}
+	var body validationResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+	if !body.Valid || body.Issuer != "Visa" {
+		t.Errorf("unexpected response: %+v", body)
+	}
+
+	// Validation errors are reported just like for plain requests.
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, newJWERequest(t, &key.PublicKey, "k1", cardRequest{
+		CardNumber:     "4539983514929271",
+		ExpirationDate: anyPastDate(),
+	}))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected status code: %d", rec.Code)
+	}
+}
+
+func TestValidationHandler_JWEErrors(t *testing.T) {
+	cfg, key := newJWEConfig(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := cardRequest{CardNumber: "4539983514929271", ExpirationDate: anyFutureDate()}
+
+	garbage := httptest.NewRequest("POST", "/validate", strings.NewReader("not.a.jwe"))
+	garbage.Header.Set("Content-Type", "application/jose")
+
+	tests := []struct {
+		name   string
+		cfg    Config
+		req    *http.Request
+		status int
+	}{
+		{"not enabled", Config{}, newJWERequest(t, &key.PublicKey, "k1", valid), http.StatusUnsupportedMediaType},
+		{"malformed", cfg, garbage, http.StatusBadRequest},
+		{"unknown kid", cfg, newJWERequest(t, &key.PublicKey, "k2", valid), http.StatusBadRequest},
+		{"wrong key", cfg, newJWERequest(t, &other.PublicKey, "", valid), http.StatusBadRequest},
+		{"not JSON", cfg, newJWERequest(t, &key.PublicKey, "k1", "just a string"), http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		rec := httptest.NewRecorder()
+		ValidationHandler(tc.cfg).ServeHTTP(rec, tc.req)
+		if rec.Code != tc.status {
+			t.Errorf("%s: unexpected status code: want %d have %d", tc.name, tc.status, rec.Code)
+		}
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	cfg, key := newJWEConfig(t)
+
+	rec := httptest.NewRecorder()
+	JWKSHandler(cfg).ServeHTTP(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", rec.Code)
+	}
+
+	var jwks jwe.JWKS
+	if err := json.NewDecoder(rec.Body).Decode(&jwks); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kid != "k1" {
+		t.Fatalf("unexpected key set: %+v", jwks)
+	}
+	pub, err := jwks.Keys[0].PublicKey()
+	if err != nil || !pub.Equal(&key.PublicKey) {
+		t.Errorf("published key doesn't match: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	JWKSHandler(Config{}).ServeHTTP(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unexpected status code without keys: %d", rec.Code)
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate/vault"
+)
+
+// Scope grants a vault client access to a vault route.
+type Scope string
+
+const (
+	ScopeTokenize   Scope = "tokenize"
+	ScopeDetokenize Scope = "detokenize"
+)
+
+// ParseVaultClients parses vault clients' bearer tokens and their scopes from a comma-separated
+// list of token:scope pairs, where multiple scopes are joined with '+', e.g.
+// "s3cr3t:tokenize,0th3r:tokenize+detokenize".
+func ParseVaultClients(s string) (map[string][]Scope, error) {
+	clients := make(map[string][]Scope)
+	for _, pair := range strings.Split(s, ",") {
+		token, scopes, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || token == "" {
+			return nil, fmt.Errorf("invalid vault client %q: expected token:scope", pair)
+		}
+		for _, scope := range strings.Split(scopes, "+") {
+			switch sc := Scope(scope); sc {
+			case ScopeTokenize, ScopeDetokenize:
+				clients[token] = append(clients[token], sc)
+			default:
+				return nil, fmt.Errorf("invalid vault client scope %q", scope)
+			}
+		}
+	}
+	return clients, nil
+}
+
+// VaultHandler returns a handler for tokenization routes:
+//
+//	POST /tokenize
+//	POST /detokenize
+//
+// Each route requires a bearer token from cfg.VaultClients with the matching scope.
+func VaultHandler(cfg Config) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST /tokenize", vaultRoute(cfg, ScopeTokenize, handleTokenize))
+	mux.Handle("POST /detokenize", vaultRoute(cfg, ScopeDetokenize, handleDetokenize))
+	return mux
+}
+
+// vaultRoute wraps a vault handler with authorization and error rendering.
+func vaultRoute(cfg Config, scope Scope, h func(Config, http.ResponseWriter, *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := authorizeVault(cfg, scope, r)
+		if err == nil {
+			err = h(cfg, w, r)
+		}
+		if err == nil {
+			return
+		}
+
+		res := asAPIError(err)
+		renderJSON(w, res.StatusCode, errorResponse{Error: res})
+	})
+}
+
+// authorizeVault checks that r carries a vault client's bearer token with scope.
+func authorizeVault(cfg Config, scope Scope, r *http.Request) error {
+	if cfg.Vault == nil {
+		return &apiError{
+			StatusCode: http.StatusNotFound,
+			Code:       errNotFound,
+			Message:    "Token vault is not configured",
+		}
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	var scopes []Scope
+	if ok {
+		// Compare against every client so that timing doesn't reveal valid tokens.
+		for t, s := range cfg.VaultClients {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				scopes = s
+			}
+		}
+	}
+	if scopes == nil {
+		return &apiError{
+			StatusCode: http.StatusUnauthorized,
+			Code:       errUnauthorized,
+			Message:    "Unauthorized",
+		}
+	}
+	if !slices.Contains(scopes, scope) {
+		return &apiError{
+			StatusCode: http.StatusForbidden,
+			Code:       errUnauthorized,
+			Message:    fmt.Sprintf("Missing %s scope", scope),
+		}
+	}
+	return nil
+}
+
+// tokenInfo is a token payload of tokenization routes.
+type tokenInfo struct {
+	Token string `json:"token"`
+}
+
+// handleTokenize validates a card and replaces its number with a token.
+func handleTokenize(cfg Config, w http.ResponseWriter, r *http.Request) error {
+	ccInfo, err := decodeCardInfo(cfg, r)
+	if err != nil {
+		return err
+	}
+	defer ccInfo.CardNumber.Zero()
+
+	// Only valid cards are tokenized.
+	v := newValidator(cfg, r)
+	result, err := v.ValidatePAN(ccInfo.CardNumber, ccInfo.ExpirationDate)
+	if err != nil {
+		return validationError(err, result, nil)
+	}
+
+	token, err := cfg.Vault.Tokenize(ccInfo.CardNumber, v.Registry)
+	if err != nil {
+		if errors.Is(err, vault.ErrInvalidPAN) {
+			return &apiError{
+				StatusCode: http.StatusUnprocessableEntity,
+				Code:       errInvalidAccountNumber,
+				Message:    "Card number can't be tokenized",
+				OrigError:  err,
+			}
+		}
+		return err
+	}
+	return renderJSON(w, http.StatusOK, tokenInfo{Token: token})
+}
+
+// detokenizeResponse is a response structure for detokenization. It carries the raw card number.
+type detokenizeResponse struct {
+	CardNumber string `json:"number"`
+}
+
+// handleDetokenize returns the card number a token stands for.
+func handleDetokenize(cfg Config, w http.ResponseWriter, r *http.Request) error {
+	req, err := decodeJSON[tokenInfo](r)
+	if err != nil {
+		return &apiError{
+			StatusCode: http.StatusBadRequest,
+			Code:       errGeneralError,
+			Message:    "Invalid JSON request",
+		}
+	}
+
+	pan, err := cfg.Vault.Detokenize(req.Token)
+	if err != nil {
+		if errors.Is(err, vault.ErrNotFound) {
+			return &apiError{
+				StatusCode: http.StatusNotFound,
+				Code:       errNotFound,
+				Message:    "Token not found",
+				OrigError:  err,
+			}
+		}
+		return err
+	}
+	defer pan.Zero()
+	return renderJSON(w, http.StatusOK, detokenizeResponse{CardNumber: pan.Raw()})
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate/vault"
+)
+
+// newVaultConfig returns a config with an in-memory vault and two clients.
+func newVaultConfig(t *testing.T) Config {
+	v, err := vault.Open("", bytes.Repeat([]byte{1}, vault.KeySize), vault.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Config{
+		Vault: v,
+		VaultClients: map[string][]Scope{
+			"merchant": {ScopeTokenize},
+			"settle":   {ScopeTokenize, ScopeDetokenize},
+		},
+	}
+}
+
+// vaultRequest sends a request to the vault handler with a bearer token.
+func vaultRequest(t *testing.T, h http.Handler, target, token string, body any) *http.Response {
+	req := newJSONRequest(t, "POST", target, body)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func TestVaultHandler(t *testing.T) {
+	h := VaultHandler(newVaultConfig(t))
+
+	res := vaultRequest(t, h, "/tokenize", "merchant", cardRequest{
+		CardNumber:     "4539983514929271",
+		ExpirationDate: anyFutureDate(),
+	})
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %s", res.Status)
+	}
+	var tok tokenInfo
+	if err := json.NewDecoder(res.Body).Decode(&tok); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+	if len(tok.Token) != 16 || tok.Token == "4539983514929271" {
+		t.Fatalf("unexpected token: %s", tok.Token)
+	}
+
+	// The tokenize scope doesn't allow detokenization.
+	if res := vaultRequest(t, h, "/detokenize", "merchant", tok); res.StatusCode != http.StatusForbidden {
+		t.Errorf("unexpected status code: %s", res.Status)
+	}
+
+	res = vaultRequest(t, h, "/detokenize", "settle", tok)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %s", res.Status)
+	}
+	var detok detokenizeResponse
+	if err := json.NewDecoder(res.Body).Decode(&detok); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+	if detok.CardNumber != "4539983514929271" {
+		t.Errorf("unexpected card number: %s", detok.CardNumber)
+	}
+
+	if res := vaultRequest(t, h, "/detokenize", "settle", tokenInfo{Token: "9999999999999995"}); res.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected status code for an unknown token: %s", res.Status)
+	}
+}
+
+func TestVaultHandler_Errors(t *testing.T) {
+	h := VaultHandler(newVaultConfig(t))
+	valid := cardRequest{CardNumber: "4539983514929271", ExpirationDate: anyFutureDate()}
+
+	tests := []struct {
+		name   string
+		token  string
+		body   any
+		status int
+		code   apiErrorCode
+	}{
+		{"missing token", "", valid, http.StatusUnauthorized, errUnauthorized},
+		{"wrong token", "nope", valid, http.StatusUnauthorized, errUnauthorized},
+		{"invalid card", "merchant", cardRequest{CardNumber: "4539983514929272", ExpirationDate: anyFutureDate()},
+			http.StatusUnprocessableEntity, errInvalidAccountNumber},
+		{"expired card", "merchant", cardRequest{CardNumber: "4539983514929271", ExpirationDate: anyPastDate()},
+			http.StatusUnprocessableEntity, errCardExpired},
+	}
+
+	for _, tc := range tests {
+		res := vaultRequest(t, h, "/tokenize", tc.token, tc.body)
+		if res.StatusCode != tc.status {
+			t.Errorf("%s: unexpected status code: want %d have %s", tc.name, tc.status, res.Status)
+			continue
+		}
+		var body errorResponse
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: error parsing JSON response: %s", tc.name, err)
+		}
+		if body.Error.Code != tc.code {
+			t.Errorf("%s: API error code mismatch: want %d have %d", tc.name, tc.code, body.Error.Code)
+		}
+	}
+
+	if res := vaultRequest(t, VaultHandler(Config{}), "/tokenize", "merchant", valid); res.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected status code without a vault: %s", res.Status)
+	}
+
+	// A 13-digit card keeping its BIN and last 4 leaves too few digits to generate a token from.
+	cfg := newVaultConfig(t)
+	v, err := vault.Open("", bytes.Repeat([]byte{1}, vault.KeySize), vault.Options{KeepBIN: true, KeepLast4: true, AllowIssuerRanges: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Vault = v
+	short := cardRequest{CardNumber: "6214839051720", ExpirationDate: anyFutureDate()}
+	res := vaultRequest(t, VaultHandler(cfg), "/tokenize", "merchant", short)
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("unexpected status code for a short card: %s", res.Status)
+	}
+	var body errorResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("error parsing JSON response: %s", err)
+	}
+	if body.Error.Code != errInvalidAccountNumber {
+		t.Errorf("API error code mismatch: want %d have %d", errInvalidAccountNumber, body.Error.Code)
+	}
+}
+
+func TestParseVaultClients(t *testing.T) {
+	clients, err := ParseVaultClients("a:tokenize, b:tokenize+detokenize")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(clients["a"]) != 1 || len(clients["b"]) != 2 || clients["b"][1] != ScopeDetokenize {
+		t.Errorf("unexpected clients: %v", clients)
+	}
+
+	for _, s := range []string{"", "a", ":tokenize", "a:admin"} {
+		if _, err := ParseVaultClients(s); err == nil {
+			t.Errorf("ParseVaultClients(%q): expected an error", s)
+		}
+	}
+}
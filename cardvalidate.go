@@ -4,7 +4,6 @@ package cardvalidate
 import (
 	"errors"
 	"fmt"
-	"slices"
 	"time"
 
 	"github.com/waterfountain1996/cardvalidate/issuer"
@@ -21,39 +20,220 @@ var (
 	ErrInvalidAccountNumber = errors.New("cardvalidate: invalid account number")
 )
 
+// Result holds details about a validated credit card.
+type Result struct {
+	Issuer          issuer.Issuer
+	IssuerName      string          // Issuer name, differs from Issuer.String() for private-label cards.
+	Checksum        issuer.Checksum // Check digit algorithm applied to the card number.
+	RegistryVersion string          // Version of the issuer registry used for validation.
+	BIN             issuer.BINInfo  // BIN database record of the card number, zero if there is none.
+	Findings        []Finding       // Advisory signals found during validation.
+}
+
+// Warnings returns findings with at least warning severity.
+func (r Result) Warnings() []Finding {
+	var warnings []Finding
+	for _, f := range r.Findings {
+		if f.Severity >= SeverityWarning {
+			warnings = append(warnings, f)
+		}
+	}
+	return warnings
+}
+
+// addFinding records a finding in r.
+func (r *Result) addFinding(code string, severity Severity, message string) {
+	r.Findings = append(r.Findings, Finding{
+		Code:     code,
+		Severity: severity,
+		Message:  message,
+	})
+}
+
 // Validate validates credit card number and its expiration date.
 // Validate only checks that cardNumber is structured accoring to ISO/IEC 7812, not
 // whether it's an actual valid account number.
 func Validate(cardNumber, expDate string) error {
+	_, err := ValidateCard(cardNumber, expDate)
+	return err
+}
+
+// ValidateCard is like Validate but also reports the card's issuer and the check digit
+// algorithm that was applied to its number.
+func ValidateCard(cardNumber, expDate string) (Result, error) {
 	return validate(cardNumber, expDate, time.Now().UTC())
 }
 
-// Validate validates credit card number and its expiration date against currentDate.
-func validate(cardNumber, expDate string, currentDate time.Time) error {
+// ValidateAt is like ValidateCard but checks the card as of at instead of the current time,
+// e.g. to tell whether it will still be valid on a future charge date.
+func ValidateAt(cardNumber, expDate string, at time.Time) (Result, error) {
+	return validate(cardNumber, expDate, at.UTC())
+}
+
+// validate validates credit card number and its expiration date against currentDate
+// using the default Validator.
+func validate(cardNumber, expDate string, currentDate time.Time) (Result, error) {
+	var v Validator
+	return v.validate(NewPAN(cardNumber), expDate, currentDate)
+}
+
+// Validator validates credit card information according to its configuration.
+// The zero value validates against the built-in issuer registry.
+type Validator struct {
+	// Registry holds additional IIN ranges, such as a tenant's private-label cards.
+	// It is consulted before the built-in registry.
+	Registry *issuer.Registry
+
+	// Lenient makes unknown IINs a warning instead of an error as long as the card number
+	// has a valid length and passes Luhn's check. Known IINs still have to have a length
+	// their issuer uses.
+	Lenient bool
+
+	// TestCardMode controls how well-known test card numbers are treated.
+	TestCardMode TestCardMode
+
+	// TestCards is the list of known test card numbers, the built-in one if nil.
+	TestCards *TestCardList
+
+	// PatternMode controls how card numbers with synthetic digit patterns are treated.
+	PatternMode PatternMode
+
+	// Detectors look for synthetic digit patterns, DefaultDetectors if nil.
+	Detectors []Detector
+
+	// BINDatabase adds funding type, product level, issuing bank and country to results,
+	// optional.
+	BINDatabase *issuer.BINDatabase
+}
+
+// Validate is like ValidateCard but uses v's configuration.
+func (v *Validator) Validate(cardNumber, expDate string) (Result, error) {
+	return v.validate(NewPAN(cardNumber), expDate, time.Now().UTC())
+}
+
+// ValidatePAN is like Validate but takes the card number as a PAN.
+func (v *Validator) ValidatePAN(pan PAN, expDate string) (Result, error) {
+	return v.validate(pan, expDate, time.Now().UTC())
+}
+
+// ValidateAt is like the package-level ValidateAt but uses v's configuration.
+func (v *Validator) ValidateAt(cardNumber, expDate string, at time.Time) (Result, error) {
+	return v.validate(NewPAN(cardNumber), expDate, at.UTC())
+}
+
+// validate validates credit card number and its expiration date against currentDate.
+func (v *Validator) validate(pan PAN, expDate string, currentDate time.Time) (Result, error) {
+	res := Result{RegistryVersion: issuer.Version()}
+	cardNumber := pan.Raw()
+
 	if !validCardNumber(cardNumber) {
-		return ErrMalformedNumber
+		return res, ErrMalformedNumber
 	}
 
-	if i := issuer.Identify(cardNumber); i == issuer.Unknown {
-		return ErrUnknownIssuer
+	entry, ok := v.lookup(cardNumber, currentDate, &res)
+	if !ok {
+		if !v.Lenient || v.knownIIN(cardNumber, currentDate) {
+			return res, ErrUnknownIssuer
+		}
+		entry = issuer.Entry{Name: "unknown", Checksum: issuer.Luhn}
+		res.addFinding(CodeUnknownIIN, SeverityWarning, "Unknown IIN")
 	}
 
-	if !luhnCheck(cardNumber) {
-		return ErrInvalidAccountNumber
+	res.Issuer = entry.Issuer
+	res.IssuerName = entry.IssuerName()
+	res.Checksum = entry.Checksum
+	res.BIN, _ = v.BINDatabase.BIN(cardNumber)
+
+	if !entry.Checksum.Verify(cardNumber) {
+		return res, ErrInvalidAccountNumber
 	}
 
-	exp, err := time.Parse(expDateLayout, expDate)
+	if err := v.checkTestCard(cardNumber, &res); err != nil {
+		return res, err
+	}
+
+	if err := v.checkPatterns(cardNumber, &res); err != nil {
+		return res, err
+	}
+
+	if !entry.IsTypicalLength(len(cardNumber)) {
+		res.addFinding(CodeUnusualLength, SeverityInfo,
+			fmt.Sprintf("Unusual card number length for %s", res.IssuerName))
+	}
+
+	exp, err := parseExpDate(expDate)
 	if err != nil {
-		return fmt.Errorf("%w: %s", ErrMalformedDate, expDate)
+		return res, err
+	}
+
+	if expiredAt(exp, currentDate) {
+		return res, ErrCardExpired
+	}
+
+	if exp.Sub(currentDate) <= ExpiresSoonPeriod {
+		res.addFinding(CodeExpiresSoon, SeverityWarning, "Credit card expires soon")
+	}
+
+	return res, nil
+}
+
+// lookup finds the registry entry for cardNumber and records the version of the registry
+// it came from in res.
+func (v *Validator) lookup(cardNumber string, currentDate time.Time, res *Result) (issuer.Entry, bool) {
+	if v.Registry != nil {
+		if entry, ok := v.Registry.LookupAt(cardNumber, currentDate); ok {
+			res.RegistryVersion = v.Registry.Version()
+			return entry, true
+		}
 	}
+	return issuer.LookupAt(cardNumber, currentDate)
+}
 
-	if exp.Before(currentDate) || exp.Equal(currentDate) {
-		return ErrCardExpired
+// knownIIN checks if any registry entry in use at currentDate matches cardNumber's IIN,
+// whatever the card number's length.
+func (v *Validator) knownIIN(cardNumber string, currentDate time.Time) bool {
+	if v.Registry != nil && len(v.Registry.MatchAt(cardNumber, currentDate)) > 0 {
+		return true
 	}
+	return len(issuer.MatchAt(cardNumber, currentDate)) > 0
+}
 
+// checkTestCard flags or rejects cardNumber if it's a well-known test card.
+func (v *Validator) checkTestCard(cardNumber string, res *Result) error {
+	if v.TestCardMode == TestCardsAllow {
+		return nil
+	}
+
+	list := v.TestCards
+	if list == nil {
+		list = defaultTestCards
+	}
+
+	source, ok := list.Lookup(cardNumber)
+	if !ok {
+		return nil
+	}
+	if v.TestCardMode == TestCardsReject {
+		return ErrTestCard
+	}
+	res.addFinding(CodeTestCard, SeverityWarning, fmt.Sprintf("Test card number published by %s", source))
 	return nil
 }
 
+// parseExpDate parses credit card's expiration date.
+func parseExpDate(expDate string) (time.Time, error) {
+	exp, err := time.Parse(expDateLayout, expDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrMalformedDate, expDate)
+	}
+	return exp, nil
+}
+
+// expiredAt checks if a card with expiration date exp has expired by t.
+func expiredAt(exp, t time.Time) bool {
+	return !exp.After(t)
+}
+
 // validCardNumber checks if cardNumber only consists of digits.
 func validCardNumber(cardNumber string) bool {
 	if len(cardNumber) < 8 || len(cardNumber) > 19 {
@@ -72,27 +252,3 @@ func validCardNumber(cardNumber string) bool {
 func isDigit(r rune) bool {
 	return '0' <= r && r <= '9'
 }
-
-// luhnCheck does Luhn's check (mod 10 check) validates credit card number.
-func luhnCheck(cardNumber string) bool {
-	digits := []rune(cardNumber)
-	slices.Reverse(digits)
-
-	sum := 0
-	for i, d := range digits {
-		n := int(d - '0')
-
-		multiplier := 1
-		if i%2 != 0 {
-			multiplier = 2
-		}
-
-		x := n * multiplier
-		if x > 9 {
-			x -= 9
-		}
-
-		sum += x
-	}
-	return sum%10 == 0
-}
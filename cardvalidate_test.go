@@ -2,8 +2,11 @@ package cardvalidate
 
 import (
 	"errors"
+	"slices"
 	"testing"
 	"time"
+
+	"github.com/waterfountain1996/cardvalidate/issuer"
 )
 
 func TestValidateValid(t *testing.T) {
@@ -93,7 +96,7 @@ func TestValidateValid(t *testing.T) {
 	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	for _, tc := range tests {
-		err := validate(tc.number, tc.expDate, currentDate)
+		_, err := validate(tc.number, tc.expDate, currentDate)
 		if err != nil {
 			t.Errorf("unexpected validation error (%s, %s): %s", tc.number, tc.expDate, err)
 		}
@@ -142,10 +145,247 @@ func TestValidateInvalid(t *testing.T) {
 	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	for _, tc := range tests {
-		err := validate(tc.number, tc.expDate, currentDate)
+		_, err := validate(tc.number, tc.expDate, currentDate)
 		if !errors.Is(err, tc.err) {
 			t.Errorf("unexpected error (%s, %s): want %s have %s",
 				tc.number, tc.expDate, tc.err, err)
 		}
 	}
 }
+
+func TestValidateChecksum(t *testing.T) {
+	tests := []struct {
+		number   string
+		checksum issuer.Checksum
+		err      error
+	}{
+		{"4111111111111111", issuer.Luhn, nil},
+		{"621234567890000002", issuer.Luhn, nil},
+		// 19-digit cards of the domestic UnionPay debit ranges don't always carry a Luhn check digit.
+		{"6212345678900000003", issuer.NoChecksum, nil},
+		{"6212345678900000004", issuer.NoChecksum, nil},
+		{"6230123456789000001", issuer.NoChecksum, nil},
+		// Shorter ones and other 19-digit UnionPay ranges still have to pass Luhn's check.
+		{"62123456789000003", issuer.Luhn, nil},
+		{"62123456789000004", issuer.Luhn, ErrInvalidAccountNumber},
+		{"6282123456789000001", issuer.Luhn, nil},
+		{"6282123456789000002", issuer.Luhn, ErrInvalidAccountNumber},
+		{"6221263456789000008", issuer.Luhn, ErrInvalidAccountNumber},
+		// Other issuers don't have 19-digit ranges without a checksum.
+		{"4111111111111111112", issuer.Checksum{}, ErrUnknownIssuer},
+	}
+
+	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range tests {
+		res, err := validate(tc.number, "08/2028", currentDate)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("unexpected validation error (%s): want %v have %v", tc.number, tc.err, err)
+			continue
+		}
+
+		if res.Checksum.Name() != tc.checksum.Name() {
+			t.Errorf("checksum mismatch (%s): want %s have %s", tc.number, tc.checksum, res.Checksum)
+		}
+	}
+}
+
+func TestValidateEffectiveDate(t *testing.T) {
+	// MasterCard 2-series BIN that only went live in October 2016.
+	const number = "2223000048400011"
+
+	if _, err := validate(number, "08/2016", time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrUnknownIssuer) {
+		t.Errorf("unexpected error before 2-series activation: want %s have %v", ErrUnknownIssuer, err)
+	}
+
+	res, err := validate(number, "08/2028", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+	if res.Issuer != issuer.MasterCard {
+		t.Errorf("unexpected issuer: want %s have %s", issuer.MasterCard, res.Issuer)
+	}
+	if res.RegistryVersion != issuer.Version() {
+		t.Errorf("registry version mismatch: want %s have %s", issuer.Version(), res.RegistryVersion)
+	}
+}
+
+func TestValidatorRegistry(t *testing.T) {
+	reg := issuer.MustNewRegistry([]issuer.Entry{
+		{
+			Issuer:   issuer.PrivateLabel,
+			Name:     "Acme Fleet",
+			Prefix:   issuer.NewIntRange(7000, 7009),
+			Length:   issuer.NewSingleIntRange(16),
+			Checksum: issuer.NoChecksum,
+		},
+	})
+	v := &Validator{Registry: reg}
+	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	res, err := v.validate(NewPAN("7005123412341234"), "08/2028", currentDate)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+	if res.IssuerName != "Acme Fleet" || res.RegistryVersion != reg.Version() {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	// Built-in ranges are still recognized.
+	res, err = v.validate(NewPAN("4111111111111111"), "08/2028", currentDate)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+	if res.Issuer != issuer.Visa || res.RegistryVersion != issuer.Version() {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	// Private-label ranges don't leak into the default validator.
+	if _, err := validate("7005123412341234", "08/2028", currentDate); !errors.Is(err, ErrUnknownIssuer) {
+		t.Errorf("unexpected error: want %s have %v", ErrUnknownIssuer, err)
+	}
+}
+
+func TestValidatorLenient(t *testing.T) {
+	v := &Validator{Lenient: true}
+	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	res, err := v.validate(NewPAN("9111111111111110"), "08/2028", currentDate)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+	if res.Issuer != issuer.Unknown || res.IssuerName != "unknown" {
+		t.Errorf("unexpected issuer: %s (%s)", res.Issuer, res.IssuerName)
+	}
+	if w := res.Warnings(); len(w) != 1 || w[0].Code != CodeUnknownIIN {
+		t.Errorf("unexpected warnings: %v", w)
+	}
+
+	// Unknown IINs still have to pass Luhn's check.
+	if _, err := v.validate(NewPAN("9111111111111111"), "08/2028", currentDate); !errors.Is(err, ErrInvalidAccountNumber) {
+		t.Errorf("unexpected error: want %s have %v", ErrInvalidAccountNumber, err)
+	}
+
+	res, err = v.validate(NewPAN("4111111111111111"), "08/2028", currentDate)
+	if err != nil || len(res.Findings) != 0 {
+		t.Errorf("unexpected result for known issuer: %+v (%v)", res, err)
+	}
+
+	// Known IINs with a length their issuer doesn't use aren't let through.
+	if _, err := v.validate(NewPAN("411111111111116"), "08/2028", currentDate); !errors.Is(err, ErrUnknownIssuer) {
+		t.Errorf("15-digit Visa: unexpected error: want %s have %v", ErrUnknownIssuer, err)
+	}
+	reg, err := issuer.NewRegistry([]issuer.Entry{{
+		Issuer:   issuer.PrivateLabel,
+		Name:     "Acme Fleet",
+		Prefix:   issuer.NewSingleIntRange(7000),
+		Length:   issuer.NewSingleIntRange(16),
+		Checksum: issuer.Luhn,
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	v.Registry = reg
+	if _, err := v.validate(NewPAN("700011111111118"), "08/2028", currentDate); !errors.Is(err, ErrUnknownIssuer) {
+		t.Errorf("15-digit tenant card: unexpected error: want %s have %v", ErrUnknownIssuer, err)
+	}
+}
+
+func TestValidateFindings(t *testing.T) {
+	tests := []struct {
+		number      string
+		expDate     string
+		currentDate time.Time
+		codes       []string
+	}{
+		{"4111111111111111", "08/2028", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nil},
+		{"4111111111111111", "02/2024", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), []string{CodeExpiresSoon}},
+		{"4111111111111111", "02/2024", time.Date(2023, 12, 5, 0, 0, 0, 0, time.UTC), nil},
+		{"62123456789002", "08/2028", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), []string{CodeUnusualLength}},
+		{"6212345678901265", "08/2028", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), nil},
+	}
+
+	for _, tc := range tests {
+		res, err := validate(tc.number, tc.expDate, tc.currentDate)
+		if err != nil {
+			t.Errorf("unexpected validation error (%s, %s): %s", tc.number, tc.expDate, err)
+			continue
+		}
+
+		var codes []string
+		for _, f := range res.Findings {
+			codes = append(codes, f.Code)
+		}
+		if !slices.Equal(codes, tc.codes) {
+			t.Errorf("findings mismatch (%s, %s): want %v have %v", tc.number, tc.expDate, tc.codes, codes)
+		}
+	}
+}
+
+func TestValidatorBINDatabase(t *testing.T) {
+	db, err := issuer.LoadBINDatabase("issuer/testdata/bins.csv")
+	if err != nil {
+		t.Fatalf("error loading BIN database: %s", err)
+	}
+	v := &Validator{BINDatabase: db}
+	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	res, err := v.validate(NewPAN("5555555555554444"), "08/2028", currentDate)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+	want := issuer.BINInfo{BIN: "555555", Funding: issuer.FundingCredit, Level: "standard", Bank: "Example Savings", Country: "GB"}
+	if res.BIN != want {
+		t.Errorf("unexpected BIN record: %+v", res.BIN)
+	}
+
+	if res, _ := v.validate(NewPAN("4012888888881881"), "08/2028", currentDate); res.BIN != (issuer.BINInfo{}) {
+		t.Errorf("expected no BIN record, got %+v", res.BIN)
+	}
+}
+
+func TestValidatorTestCards(t *testing.T) {
+	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	v := &Validator{TestCardMode: TestCardsWarn}
+	res, err := v.validate(NewPAN("4242424242424242"), "08/2028", currentDate)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+	if w := res.Warnings(); len(w) != 1 || w[0].Code != CodeTestCard {
+		t.Errorf("unexpected warnings: %v", w)
+	}
+
+	v = &Validator{TestCardMode: TestCardsReject}
+	if _, err := v.validate(NewPAN("5555555555554444"), "08/2028", currentDate); !errors.Is(err, ErrTestCard) {
+		t.Errorf("unexpected error: want %s have %v", ErrTestCard, err)
+	}
+	if _, err := v.validate(NewPAN("4539983514929271"), "08/2028", currentDate); err != nil {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+
+	list := NewTestCardList()
+	list.Add("4539983514929271", "Internal QA")
+	v = &Validator{TestCardMode: TestCardsReject, TestCards: list}
+	if _, err := v.validate(NewPAN("4539983514929271"), "08/2028", currentDate); !errors.Is(err, ErrTestCard) {
+		t.Errorf("unexpected error: want %s have %v", ErrTestCard, err)
+	}
+	if _, ok := defaultTestCards.Lookup("4539983514929271"); ok {
+		t.Errorf("custom test card leaked into the built-in list")
+	}
+}
+
+func TestBuiltinTestCards(t *testing.T) {
+	v := &Validator{TestCardMode: TestCardsReject}
+	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for number, source := range builtinTestCards {
+		if !validCardNumber(number) || !issuer.Luhn.Verify(number) {
+			t.Errorf("malformed %s test card number: %s", source, number)
+		}
+		// Test cards are only detected in card numbers that pass issuer and checksum validation.
+		if _, err := v.validate(NewPAN(number), "08/2028", currentDate); !errors.Is(err, ErrTestCard) {
+			t.Errorf("%s test card number %s isn't detected: %v", source, number, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Command cardvalidate provides command-line tools for working with cardholder data.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate/scan"
+)
+
+// Exit codes suitable for pre-commit hooks and CI gates.
+const (
+	exitClean    = 0 // No card numbers were found.
+	exitFindings = 1 // Card numbers were found.
+	exitError    = 2 // Invalid usage or files that couldn't be scanned.
+)
+
+const usage = `Usage: cardvalidate <command> [flags]
+
+Commands:
+  scan    find card numbers in files, directories and archives
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command given by args and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+
+	switch args[0] {
+	case "scan":
+		return runScan(args[1:], stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitClean
+	default:
+		fmt.Fprintf(stderr, "cardvalidate: unknown command %q\n\n%s", args[0], usage)
+		return exitError
+	}
+}
+
+// runScan implements the scan command.
+func runScan(args []string, stdout, stderr io.Writer) int {
+	var (
+		opts   scan.Options
+		format string
+		output string
+	)
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: cardvalidate scan [flags] [path ...]\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Var((*globs)(&opts.Include), "include", "glob of files to scan, may be repeated (default all files)")
+	fs.Var((*globs)(&opts.Exclude), "exclude", "glob of files and directories to skip, may be repeated")
+	fs.IntVar(&opts.Workers, "workers", 0, "number of files scanned in parallel (default number of CPUs)")
+	fs.Float64Var(&opts.MinScore, "min-score", 0.5, "lowest confidence score to report, from 0 to 1")
+	fs.StringVar(&format, "format", "text", "output format: text, json or sarif")
+	fs.StringVar(&output, "o", "", "write results to file instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitClean
+		}
+		return exitError
+	}
+
+	write, ok := reporters[format]
+	if !ok {
+		fmt.Fprintf(stderr, "cardvalidate: unknown format %q\n", format)
+		return exitError
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	matches, scanErr := scan.Files(paths, opts)
+	if scanErr != nil {
+		fmt.Fprintf(stderr, "cardvalidate: %s\n", scanErr)
+	}
+
+	if err := writeResults(write, output, stdout, matches); err != nil {
+		fmt.Fprintf(stderr, "cardvalidate: error writing results: %s\n", err)
+		return exitError
+	}
+
+	switch {
+	case scanErr != nil:
+		return exitError
+	case len(matches) > 0:
+		return exitFindings
+	default:
+		return exitClean
+	}
+}
+
+// writeResults writes matches with write to the file at path, or to stdout if path is empty.
+func writeResults(write reporter, path string, stdout io.Writer, matches []scan.FileMatch) error {
+	if path == "" {
+		return write(stdout, matches)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = write(f, matches)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// globs is a flag that may be repeated or hold comma-separated glob patterns.
+type globs []string
+
+// String implements flag.Value.
+func (g *globs) String() string {
+	return strings.Join(*g, ",")
+}
+
+// Set implements flag.Value.
+func (g *globs) Set(v string) error {
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			*g = append(*g, p)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testDir creates a directory with a plain file and a zip archive that hold card numbers.
+func testDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "plain.log"), []byte("ok\ncard 4111111111111111\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("inner!1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("pan=5555555555554444\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "archive.zip"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunExitCodes(t *testing.T) {
+	dir := testDir(t)
+	clean := t.TempDir()
+	if err := os.WriteFile(filepath.Join(clean, "clean.txt"), []byte("nothing here 1234\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no command", nil, exitError},
+		{"help", []string{"help"}, exitClean},
+		{"unknown command", []string{"grep"}, exitError},
+		{"clean", []string{"scan", clean}, exitClean},
+		{"findings", []string{"scan", dir}, exitFindings},
+		{"missing path", []string{"scan", filepath.Join(clean, "missing")}, exitError},
+		{"unknown format", []string{"scan", "-format", "xml", clean}, exitError},
+		{"unknown flag", []string{"scan", "-verbose", clean}, exitError},
+		{"unwritable output", []string{"scan", "-o", filepath.Join(clean, "missing", "out.txt"), clean}, exitError},
+	}
+	for _, tc := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(tc.args, &stdout, &stderr); code != tc.code {
+			t.Errorf("%s: want exit code %d have %d, stderr: %s", tc.name, tc.code, code, stderr.String())
+		}
+	}
+}
+
+func TestRunText(t *testing.T) {
+	dir := testDir(t)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"scan", dir}, &stdout, &stderr); code != exitFindings {
+		t.Fatalf("unexpected exit code %d, stderr: %s", code, stderr.String())
+	}
+
+	want := filepath.Join(dir, "archive.zip") + "!inner!1.txt:1:5: MasterCard 555555******4444 (score 0.90)\n" +
+		filepath.Join(dir, "plain.log") + ":2:6: Visa 411111******1111 (score 0.90)\n"
+	if stdout.String() != want {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	dir := testDir(t)
+	output := filepath.Join(t.TempDir(), "results.json")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"scan", "-format", "json", "-o", output, dir}, &stdout, &stderr); code != exitFindings {
+		t.Fatalf("unexpected exit code %d, stderr: %s", code, stderr.String())
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("unexpected output on stdout: %s", stdout.String())
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "4111111111111111") {
+		t.Errorf("results contain an unmasked card number: %s", data)
+	}
+	var matches []jsonMatch
+	if err := json.Unmarshal(data, &matches); err != nil {
+		t.Fatalf("invalid JSON output: %s", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %+v", matches)
+	}
+	want := jsonMatch{Path: filepath.Join(dir, "plain.log"), Line: 2, Column: 6, Offset: 8, Issuer: "Visa", PAN: "411111******1111"}
+	if have := matches[1]; have.Score < 0.5 {
+		t.Errorf("unexpected score: %f", have.Score)
+	} else if have.Score = 0; have != want {
+		t.Errorf("want %+v have %+v", want, have)
+	}
+}
+
+func TestRunSARIF(t *testing.T) {
+	dir := testDir(t)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"scan", "-format", "sarif", dir}, &stdout, &stderr); code != exitFindings {
+		t.Fatalf("unexpected exit code %d, stderr: %s", code, stderr.String())
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(stdout.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF output: %s", err)
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("unexpected SARIF log: %+v", log)
+	}
+
+	// Matches inside archives point at the archive without a region.
+	archive := log.Runs[0].Results[0]
+	location := archive.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != filepath.ToSlash(filepath.Join(dir, "archive.zip")) || location.Region != nil {
+		t.Errorf("unexpected archive location: %+v", location)
+	}
+	if archive.Properties["archiveMember"] != "inner!1.txt" || archive.Properties["line"] != 1.0 {
+		t.Errorf("unexpected archive properties: %v", archive.Properties)
+	}
+
+	plain := log.Runs[0].Results[1]
+	location = plain.Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != filepath.ToSlash(filepath.Join(dir, "plain.log")) ||
+		location.Region == nil || *location.Region != (sarifRegion{StartLine: 2, StartColumn: 6}) {
+		t.Errorf("unexpected plain file location: %+v", location)
+	}
+	if plain.Message.Text != "Visa card number 411111******1111" {
+		t.Errorf("unexpected message: %s", plain.Message.Text)
+	}
+}
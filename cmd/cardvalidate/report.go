@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/scan"
+)
+
+// reporter writes scan results to w.
+type reporter func(w io.Writer, matches []scan.FileMatch) error
+
+// reporters maps output format names to reporters.
+var reporters = map[string]reporter{
+	"text":  writeText,
+	"json":  writeJSON,
+	"sarif": writeSARIF,
+}
+
+// writeText writes one line per match in a grep-like format.
+func writeText(w io.Writer, matches []scan.FileMatch) error {
+	for _, m := range matches {
+		_, err := fmt.Fprintf(w, "%s:%d:%d: %s %s (score %.2f)\n",
+			m.Path, m.Line, m.Column, m.Issuer, cardvalidate.MaskPAN(m.PAN), m.Score)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonMatch is a match in JSON output. The card number is always masked.
+type jsonMatch struct {
+	Path   string  `json:"path"`
+	Line   int     `json:"line"`
+	Column int     `json:"column"`
+	Offset int64   `json:"offset"`
+	Issuer string  `json:"issuer"`
+	PAN    string  `json:"pan"`
+	Score  float64 `json:"score"`
+}
+
+// writeJSON writes matches as a JSON array.
+func writeJSON(w io.Writer, matches []scan.FileMatch) error {
+	out := make([]jsonMatch, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, jsonMatch{
+			Path:   m.Path,
+			Line:   m.Line,
+			Column: m.Column,
+			Offset: m.Offset,
+			Issuer: m.Issuer.String(),
+			PAN:    cardvalidate.MaskPAN(m.PAN),
+			Score:  m.Score,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// sarifRuleID identifies card number findings in SARIF output.
+const sarifRuleID = "cardholder-data"
+
+// SARIF 2.1.0 log structure, only the parts used by the reporter.
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name  string      `json:"name"`
+		Rules []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifResult struct {
+		RuleID     string          `json:"ruleId"`
+		Level      string          `json:"level"`
+		Message    sarifMessage    `json:"message"`
+		Locations  []sarifLocation `json:"locations"`
+		Properties map[string]any  `json:"properties,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn"`
+	}
+)
+
+// writeSARIF writes matches as a SARIF 2.1.0 log for code scanning dashboards.
+// Matches inside archives and compressed files are reported against the archive file without
+// a region, since their lines don't exist in it. The member path, line and column go into the
+// result's properties instead.
+func writeSARIF(w io.Writer, matches []scan.FileMatch) error {
+	results := make([]sarifResult, 0, len(matches))
+	for _, m := range matches {
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(m.File)}}
+		properties := map[string]any{"score": m.Score}
+		if m.Member == "" && !isCompressed(m.File) {
+			location.Region = &sarifRegion{StartLine: m.Line, StartColumn: m.Column}
+		} else {
+			if m.Member != "" {
+				properties["archiveMember"] = m.Member
+			}
+			properties["line"], properties["column"] = m.Line, m.Column
+		}
+
+		results = append(results, sarifResult{
+			RuleID:     sarifRuleID,
+			Level:      "error",
+			Message:    sarifMessage{Text: fmt.Sprintf("%s card number %s", m.Issuer, cardvalidate.MaskPAN(m.PAN))},
+			Locations:  []sarifLocation{{PhysicalLocation: location}},
+			Properties: properties,
+		})
+	}
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name: "cardvalidate",
+				Rules: []sarifRule{{
+					ID:               sarifRuleID,
+					ShortDescription: sarifMessage{Text: "Unprotected card number"},
+				}},
+			}},
+			Results: results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// isCompressed checks if the file at path is gzip-compressed according to its name.
+func isCompressed(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".gz" || ext == ".tgz"
+}
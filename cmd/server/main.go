@@ -2,21 +2,111 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/waterfountain1996/cardvalidate"
 	"github.com/waterfountain1996/cardvalidate/api"
+	"github.com/waterfountain1996/cardvalidate/fingerprint"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+	"github.com/waterfountain1996/cardvalidate/jwe"
+	"github.com/waterfountain1996/cardvalidate/redact"
+	"github.com/waterfountain1996/cardvalidate/tenant"
+	"github.com/waterfountain1996/cardvalidate/vault"
 )
 
 func main() {
+	redactLogs, err := strconv.ParseBool(getenv("CARDVALIDATE_REDACT_LOGS", "true"))
+	if err != nil {
+		log.Fatalf("CARDVALIDATE_REDACT_LOGS: %s\n", err)
+	}
+	if redactLogs {
+		// The default slog logger writes through the log package, so this covers both.
+		log.SetOutput(redact.NewWriter(os.Stderr))
+	}
+
+	tenants, err := tenant.Open(getenv("CARDVALIDATE_TENANTS_FILE", "tenants.json"))
+	if err != nil {
+		log.Fatalf("tenant.Open(): %s\n", err)
+	}
+
+	lenient, err := strconv.ParseBool(getenv("CARDVALIDATE_LENIENT", "false"))
+	if err != nil {
+		log.Fatalf("CARDVALIDATE_LENIENT: %s\n", err)
+	}
+
+	testCardMode, err := cardvalidate.ParseTestCardMode(getenv("CARDVALIDATE_TEST_CARDS", "allow"))
+	if err != nil {
+		log.Fatalf("CARDVALIDATE_TEST_CARDS: %s\n", err)
+	}
+
+	patternMode, err := cardvalidate.ParsePatternMode(getenv("CARDVALIDATE_PATTERNS", "allow"))
+	if err != nil {
+		log.Fatalf("CARDVALIDATE_PATTERNS: %s\n", err)
+	}
+
+	var fingerprints *fingerprint.Keyring
+	if path := os.Getenv("CARDVALIDATE_FINGERPRINT_KEYS_FILE"); path != "" {
+		fingerprints, err = fingerprint.LoadKeyring(path)
+	} else if keys := os.Getenv("CARDVALIDATE_FINGERPRINT_KEYS"); keys != "" {
+		fingerprints, err = fingerprint.ParseKeyring(keys)
+	}
+	if err != nil {
+		log.Fatalf("fingerprint keys: %s\n", err)
+	}
+
+	tokenVault, vaultClients := openVault()
+
+	var jweKeys *jwe.KeySet
+	if dir := os.Getenv("CARDVALIDATE_JWE_KEYS_DIR"); dir != "" {
+		jweKeys, err = jwe.LoadKeySet(dir)
+		if err != nil {
+			log.Fatalf("jwe.LoadKeySet(): %s\n", err)
+		}
+	}
+
+	var bins *issuer.BINDatabase
+	if path := os.Getenv("CARDVALIDATE_BIN_DATABASE_FILE"); path != "" {
+		bins, err = issuer.LoadBINDatabase(path)
+		if err != nil {
+			log.Fatalf("issuer.LoadBINDatabase(): %s\n", err)
+		}
+	}
+
+	cfg := api.Config{
+		Tenants:      tenants,
+		AdminToken:   os.Getenv("CARDVALIDATE_ADMIN_TOKEN"),
+		Lenient:      lenient,
+		TestCardMode: testCardMode,
+		PatternMode:  patternMode,
+		Fingerprints: fingerprints,
+		Vault:        tokenVault,
+		VaultClients: vaultClients,
+		JWEKeys:      jweKeys,
+		BINDatabase:  bins,
+	}
+
 	mux := http.NewServeMux()
-	mux.Handle("POST /validate", api.ValidationHandler())
+	mux.Handle("POST /validate", api.ValidationHandler(cfg))
 	mux.Handle("GET /docs", api.SwaggerUIHandler())
 	mux.Handle("GET /openapi.json", api.OpenAPIHandler())
+	if cfg.AdminToken != "" {
+		mux.Handle("/admin/", api.AdminHandler(cfg))
+	}
+	if cfg.Vault != nil {
+		vaultHandler := api.VaultHandler(cfg)
+		mux.Handle("POST /tokenize", vaultHandler)
+		mux.Handle("POST /detokenize", vaultHandler)
+	}
+	if cfg.JWEKeys != nil {
+		mux.Handle("GET /.well-known/jwks.json", api.JWKSHandler(cfg))
+	}
 
 	httpSrv := &http.Server{
 		Addr:    ":8000",
@@ -41,3 +131,49 @@ func main() {
 		log.Fatalf("Shutdown(): %s\n", err)
 	}
 }
+
+// openVault opens the token vault if CARDVALIDATE_VAULT_KEY is set.
+func openVault() (*vault.Vault, map[string][]api.Scope) {
+	encodedKey := os.Getenv("CARDVALIDATE_VAULT_KEY")
+	if encodedKey == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		log.Fatalf("CARDVALIDATE_VAULT_KEY: %s\n", err)
+	}
+
+	opts := vault.Options{
+		KeepBIN:           getenvBool("CARDVALIDATE_VAULT_KEEP_BIN", false),
+		KeepLast4:         getenvBool("CARDVALIDATE_VAULT_KEEP_LAST4", false),
+		AllowIssuerRanges: getenvBool("CARDVALIDATE_VAULT_ALLOW_ISSUER_RANGES", false),
+	}
+	v, err := vault.Open(getenv("CARDVALIDATE_VAULT_FILE", "vault.bin"), key, opts)
+	if err != nil {
+		log.Fatalf("vault.Open(): %s\n", err)
+	}
+
+	clients, err := api.ParseVaultClients(os.Getenv("CARDVALIDATE_VAULT_CLIENTS"))
+	if err != nil {
+		log.Fatalf("CARDVALIDATE_VAULT_CLIENTS: %s\n", err)
+	}
+	return v, clients
+}
+
+// getenvBool returns the boolean value of environment variable key or fallback if it's not set.
+func getenvBool(key string, fallback bool) bool {
+	v, err := strconv.ParseBool(getenv(key, strconv.FormatBool(fallback)))
+	if err != nil {
+		log.Fatalf("%s: %s\n", key, err)
+	}
+	return v
+}
+
+// getenv returns the value of environment variable key or fallback if it's not set.
+func getenv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,149 @@
+// Package cvv checks card security code lengths and computes Visa CVV and MasterCard CVC values
+// with the 3DES-based algorithm card issuers use, for test hosts and simulators.
+//
+// The same algorithm produces all three values printed on or encoded in a card, only the
+// service code differs:
+//   - CVV1/CVC1 on the magnetic stripe uses the card's service code,
+//   - CVV2/CVC2 printed on the card uses ServiceCodeCVV2,
+//   - iCVV in the chip's track 2 equivalent data uses ServiceCodeICVV.
+package cvv
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/internal/decimal"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+var (
+	ErrInvalidKey   = errors.New("cvv: invalid key")
+	ErrInvalidInput = errors.New("cvv: invalid input")
+)
+
+// Service codes that select the kind of value computed.
+const (
+	ServiceCodeCVV2 = "000"
+	ServiceCodeICVV = "999"
+)
+
+// codeLen is the number of digits in a computed value.
+const codeLen = 3
+
+// Length returns the number of digits in the security code of iss's cards: 4 for the American
+// Express CID printed on the front and 3 for everyone else.
+func Length(iss issuer.Issuer) int {
+	if iss == issuer.AmericanExpress {
+		return 4
+	}
+	return 3
+}
+
+// ValidLength checks that code is made of as many digits as iss's security codes have.
+func ValidLength(iss issuer.Issuer, code string) bool {
+	return len(code) == Length(iss) && isDigits(code)
+}
+
+// Key is a card verification key (CVK) pair.
+type Key struct {
+	a cipher.Block // Single DES with CVK A.
+	b cipher.Block // Triple DES with CVK A, CVK B, CVK A.
+}
+
+// NewKey returns a key from hex-encoded 8-byte halves cvkA and cvkB.
+func NewKey(cvkA, cvkB string) (*Key, error) {
+	a, err := decodeKeyHalf(cvkA)
+	if err != nil {
+		return nil, fmt.Errorf("%w: CVK A: %s", ErrInvalidKey, err)
+	}
+	b, err := decodeKeyHalf(cvkB)
+	if err != nil {
+		return nil, fmt.Errorf("%w: CVK B: %s", ErrInvalidKey, err)
+	}
+
+	single, err := des.NewCipher(a)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	triple, err := des.NewTripleDESCipher(append(append(a, b...), a...))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	return &Key{a: single, b: triple}, nil
+}
+
+// ParseKey returns a key from a hex-encoded 16-byte double-length key, CVK A followed by CVK B.
+func ParseKey(s string) (*Key, error) {
+	if len(s) != 4*des.BlockSize {
+		return nil, fmt.Errorf("%w: expected %d hex digits", ErrInvalidKey, 4*des.BlockSize)
+	}
+	return NewKey(s[:2*des.BlockSize], s[2*des.BlockSize:])
+}
+
+// decodeKeyHalf decodes a hex-encoded single DES key.
+func decodeKeyHalf(s string) ([]byte, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != des.BlockSize {
+		return nil, fmt.Errorf("expected %d bytes, got %d", des.BlockSize, len(b))
+	}
+	return b, nil
+}
+
+// Compute returns the 3-digit value for pan with expiry in YYMM format and serviceCode.
+func Compute(k *Key, pan cardvalidate.PAN, expiry, serviceCode string) (string, error) {
+	digits := pan.Bytes()
+	switch {
+	case len(digits) < 12 || len(digits) > 19 || !isDigits(digits):
+		return "", fmt.Errorf("%w: card number", ErrInvalidInput)
+	case len(expiry) != 4 || !isDigits(expiry):
+		return "", fmt.Errorf("%w: expiry must be YYMM", ErrInvalidInput)
+	case len(serviceCode) != 3 || !isDigits(serviceCode):
+		return "", fmt.Errorf("%w: service code", ErrInvalidInput)
+	}
+
+	// PAN, expiry and service code right-padded with zeros to two blocks of 16 digits.
+	data := bytes.Repeat([]byte{'0'}, 4*des.BlockSize)
+	defer clear(data)
+	n := copy(data, digits)
+	n += copy(data[n:], expiry)
+	copy(data[n:], serviceCode)
+	block := make([]byte, 2*des.BlockSize)
+	defer clear(block)
+	if _, err := hex.Decode(block, data); err != nil {
+		return "", err
+	}
+
+	b1, b2 := block[:des.BlockSize], block[des.BlockSize:]
+	k.a.Encrypt(b1, b1)
+	subtle.XORBytes(b1, b1, b2)
+	k.b.Encrypt(b1, b1)
+	return decimal.FromHex(hex.EncodeToString(b1), codeLen), nil
+}
+
+// Verify checks that code is the value for pan with expiry in YYMM format and serviceCode.
+func Verify(k *Key, pan cardvalidate.PAN, expiry, serviceCode, code string) (bool, error) {
+	want, err := Compute(k, pan, expiry, serviceCode)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1, nil
+}
+
+// isDigits checks that s is made of decimal digits only.
+func isDigits[T ~string | ~[]byte](s T) bool {
+	for i := range len(s) {
+		if c := s[i]; c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
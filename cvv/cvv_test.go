@@ -0,0 +1,108 @@
+package cvv
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+func TestCompute(t *testing.T) {
+	key, err := NewKey("0123456789ABCDEF", "FEDCBA9876543210")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		pan, expiry, serviceCode string
+		want                     string
+	}{
+		// IBM CCA CVV_Generate example.
+		{"4123456789012345", "8701", "101", "561"},
+		{"4999988887777000", "9105", ServiceCodeCVV2, "091"},
+		{"4999988887777000", "9105", ServiceCodeICVV, "604"},
+	}
+
+	for _, tc := range tests {
+		pan := cardvalidate.NewPAN(tc.pan)
+		have, err := Compute(key, pan, tc.expiry, tc.serviceCode)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.pan, err)
+			continue
+		}
+		if have != tc.want {
+			t.Errorf("%s/%s/%s: want %s have %s", tc.pan, tc.expiry, tc.serviceCode, tc.want, have)
+		}
+
+		ok, err := Verify(key, pan, tc.expiry, tc.serviceCode, tc.want)
+		if err != nil || !ok {
+			t.Errorf("%s: Verify() = %t, %v", tc.pan, ok, err)
+		}
+		if ok, _ := Verify(key, pan, tc.expiry, tc.serviceCode, "000"); ok {
+			t.Errorf("%s: wrong value verified", tc.pan)
+		}
+	}
+
+	// Double-length key in one piece.
+	joined, err := ParseKey("0123456789ABCDEFFEDCBA9876543210")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if have, _ := Compute(joined, cardvalidate.NewPAN("4123456789012345"), "8701", "101"); have != "561" {
+		t.Errorf("ParseKey(): want 561 have %s", have)
+	}
+}
+
+func TestComputeErrors(t *testing.T) {
+	key, err := ParseKey("0123456789ABCDEFFEDCBA9876543210")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		pan, expiry, serviceCode string
+	}{
+		{"41234567890", "8701", "101"},
+		{"41234567890123456789", "8701", "101"},
+		{"4123456789012345", "87/01", "101"},
+		{"4123456789012345", "8701", "1x1"},
+		{"4123456789012345", "8701", "10"},
+	}
+	for _, tc := range tests {
+		_, err := Compute(key, cardvalidate.NewPAN(tc.pan), tc.expiry, tc.serviceCode)
+		if !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%+v: unexpected error: %v", tc, err)
+		}
+	}
+
+	for _, k := range []string{"", "0123456789ABCDEF", "0123456789ABCDEFFEDCBA987654321Z", "0123456789ABCDEFFEDCBA98765432"} {
+		if _, err := ParseKey(k); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ParseKey(%q): unexpected error: %v", k, err)
+		}
+	}
+	if _, err := NewKey("0123456789ABCDEF", "FEDCBA98"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("NewKey(): unexpected error: %v", err)
+	}
+}
+
+func TestValidLength(t *testing.T) {
+	tests := []struct {
+		issuer issuer.Issuer
+		code   string
+		want   bool
+	}{
+		{issuer.Visa, "123", true},
+		{issuer.MasterCard, "123", true},
+		{issuer.Visa, "1234", false},
+		{issuer.AmericanExpress, "1234", true},
+		{issuer.AmericanExpress, "123", false},
+		{issuer.Discover, "12a", false},
+		{issuer.JCB, "", false},
+	}
+	for _, tc := range tests {
+		if have := ValidLength(tc.issuer, tc.code); have != tc.want {
+			t.Errorf("ValidLength(%s, %q): want %t have %t", tc.issuer, tc.code, tc.want, have)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package dukpt
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/pin"
+)
+
+// AES key serial numbers are an 8-byte initial key ID followed by a 4-byte transaction counter.
+const (
+	aesKSNSize    = 12
+	initialIDSize = 8
+)
+
+// Key usage indicators of the derivation data.
+const (
+	usagePINEncryption  = 0x1000
+	usageDataEncryption = 0x3000
+	usageKeyDerivation  = 0x8000
+	usageInitialKey     = 0x8001
+)
+
+// aesVariants maps variants to the key usage of their working keys.
+var aesVariants = map[Variant]uint16{
+	PINVariant:  usagePINEncryption,
+	DataVariant: usageDataEncryption,
+}
+
+// AES derives AES DUKPT keys from an AES base derivation key. Working keys are of the same size
+// as the base derivation key.
+type AES struct {
+	bdk []byte
+}
+
+// NewAES returns an AES DUKPT decrypter with a 16, 24 or 32-byte base derivation key bdk.
+func NewAES(bdk []byte) (*AES, error) {
+	switch len(bdk) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%w: expected 16, 24 or 32 bytes, got %d", ErrInvalidKey, len(bdk))
+	}
+	return &AES{bdk: append([]byte(nil), bdk...)}, nil
+}
+
+// InitialKey returns the initial key loaded into the reader with key serial number ksn.
+func (d *AES) InitialKey(ksn []byte) ([]byte, error) {
+	if len(ksn) != aesKSNSize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKSN, aesKSNSize, len(ksn))
+	}
+	return d.derive(d.bdk, d.derivationData(usageInitialKey, ksn, 0))
+}
+
+// Key returns the working key of variant v for key serial number ksn.
+func (d *AES) Key(ksn []byte, v Variant) ([]byte, error) {
+	usage, ok := aesVariants[v]
+	if !ok {
+		return nil, fmt.Errorf("dukpt: unsupported variant %s", v)
+	}
+	key, err := d.InitialKey(ksn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk the counter's bits from the top, deriving an intermediate key for each one that is set.
+	counter := binary.BigEndian.Uint32(ksn[initialIDSize:])
+	var working uint32
+	for bit := uint32(1) << 31; bit > 0; bit >>= 1 {
+		if counter&bit != 0 {
+			working |= bit
+			key, err = d.derive(key, d.derivationData(usageKeyDerivation, ksn, working))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return d.derive(key, d.derivationData(usage, ksn, counter))
+}
+
+// DecryptData decrypts card data encrypted in CBC mode with a zero IV under the data key.
+func (d *AES) DecryptData(ksn, ciphertext []byte) ([]byte, error) {
+	key, err := d.Key(ksn, DataVariant)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return decryptCBC(block, ciphertext)
+}
+
+// DecryptPIN returns the PIN from a format 4 PIN block enciphered with the PIN key.
+func (d *AES) DecryptPIN(ksn []byte, f pin.Format, pinBlock []byte, pan cardvalidate.PAN) (string, error) {
+	if f != pin.Format4 {
+		return "", fmt.Errorf("dukpt: AES DUKPT requires %s PIN blocks, got %s", pin.Format4, f)
+	}
+	key, err := d.Key(ksn, PINVariant)
+	if err != nil {
+		return "", err
+	}
+	return pin.DecryptFormat4(key, pinBlock, pan)
+}
+
+// derivationData returns X9.24-3 derivation data for a key with usage. The initial key is
+// derived from the initial key ID in ksn, other keys from its rightmost 4 bytes and counter.
+func (d *AES) derivationData(usage uint16, ksn []byte, counter uint32) []byte {
+	data := make([]byte, aes.BlockSize)
+	data[0] = 0x01 // Version.
+	binary.BigEndian.PutUint16(data[2:], usage)
+	binary.BigEndian.PutUint16(data[4:], uint16(len(d.bdk)/8)) // 2 for AES-128 up to 4 for AES-256.
+	binary.BigEndian.PutUint16(data[6:], uint16(8*len(d.bdk)))
+	if usage == usageInitialKey {
+		copy(data[8:], ksn[:initialIDSize])
+	} else {
+		copy(data[8:], ksn[4:initialIDSize])
+		binary.BigEndian.PutUint32(data[12:], counter)
+	}
+	return data
+}
+
+// derive derives a key of the base derivation key's size from key and derivation data.
+func (d *AES) derive(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+
+	// Keys longer than a block are made of several blocks told apart by the key block counter.
+	out := make([]byte, 2*aes.BlockSize)
+	for i := 0; i*aes.BlockSize < len(d.bdk); i++ {
+		data[1] = byte(i + 1)
+		block.Encrypt(out[i*aes.BlockSize:], data)
+	}
+	return out[:len(d.bdk)], nil
+}
@@ -0,0 +1,87 @@
+// Package dukpt derives ANSI X9.24 DUKPT transaction keys and decrypts the card data and PIN
+// blocks that card readers encrypt under them. It implements TDES DUKPT (X9.24-1:2009) and AES
+// DUKPT (X9.24-3:2017), for hosts and test environments that hold the base derivation key.
+package dukpt
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/pin"
+	"github.com/waterfountain1996/cardvalidate/track"
+)
+
+var (
+	ErrInvalidKey        = errors.New("dukpt: invalid key")
+	ErrInvalidKSN        = errors.New("dukpt: invalid key serial number")
+	ErrInvalidCiphertext = errors.New("dukpt: invalid ciphertext")
+)
+
+// Variant selects the transaction key a payload is encrypted under.
+type Variant int
+
+const (
+	PINVariant  Variant = iota // PIN blocks.
+	DataVariant                // Card data sent by the reader.
+)
+
+// String implements fmt.Stringer.
+func (v Variant) String() string {
+	switch v {
+	case PINVariant:
+		return "PIN"
+	case DataVariant:
+		return "data"
+	default:
+		return fmt.Sprintf("Variant(%d)", int(v))
+	}
+}
+
+// Decrypter derives transaction keys from a base derivation key and decrypts reader payloads.
+type Decrypter interface {
+	// Key returns the transaction key of variant v for key serial number ksn.
+	Key(ksn []byte, v Variant) ([]byte, error)
+
+	// DecryptData decrypts card data encrypted in CBC mode with a zero IV under the data key.
+	DecryptData(ksn, ciphertext []byte) ([]byte, error)
+
+	// DecryptPIN returns the PIN from a PIN block of format f encrypted under the PIN key.
+	DecryptPIN(ksn []byte, f pin.Format, pinBlock []byte, pan cardvalidate.PAN) (string, error)
+}
+
+// DecryptTrack decrypts track 1 or track 2 data encrypted by a reader, parses it and validates
+// the card. Track data may come with or without sentinels and trailing zero padding.
+func DecryptTrack(d Decrypter, ksn, ciphertext []byte) (track.Data, cardvalidate.Result, error) {
+	plaintext, err := d.DecryptData(ksn, ciphertext)
+	if err != nil {
+		return track.Data{}, cardvalidate.Result{}, err
+	}
+	defer clear(plaintext)
+
+	raw := string(bytes.TrimRight(plaintext, "\x00"))
+	var data track.Data
+	if len(raw) > 0 && (raw[0] == '%' || raw[0] == ';') {
+		data, err = track.Parse(raw)
+	} else {
+		data, err = track.ParseTrack2Equivalent(raw)
+	}
+	if err != nil {
+		return track.Data{}, cardvalidate.Result{}, err
+	}
+
+	result, err := data.Validate()
+	return data, result, err
+}
+
+// decryptCBC decrypts ciphertext with block in CBC mode with a zero IV.
+func decryptCBC(block cipher.Block, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 || len(ciphertext)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("%w: length %d isn't a multiple of %d", ErrInvalidCiphertext, len(ciphertext), block.BlockSize())
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, make([]byte, block.BlockSize())).CryptBlocks(plaintext, ciphertext)
+	return plaintext, nil
+}
@@ -0,0 +1,219 @@
+package dukpt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+	"github.com/waterfountain1996/cardvalidate/pin"
+	"github.com/waterfountain1996/cardvalidate/track"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// encryptCBC encrypts plaintext zero-padded to whole blocks like a reader does.
+func encryptCBC(t *testing.T, block cipher.Block, plaintext string) []byte {
+	t.Helper()
+	n := (len(plaintext) + block.BlockSize() - 1) / block.BlockSize() * block.BlockSize()
+	out := make([]byte, n)
+	copy(out, plaintext)
+	cipher.NewCBCEncrypter(block, make([]byte, block.BlockSize())).CryptBlocks(out, out)
+	return out
+}
+
+// ANSI X9.24-1:2009 test vectors.
+func TestTDES(t *testing.T) {
+	d, err := NewTDES(mustHex(t, "0123456789ABCDEFFEDCBA9876543210"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ipek, err := d.IPEK(mustHex(t, "FFFF9876543210E00008"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if have := hex.EncodeToString(ipek); have != "6ac292faa1315b4d858ab3a3d7d5933a" {
+		t.Errorf("unexpected IPEK: %s", have)
+	}
+
+	tests := []struct {
+		ksn      string
+		pinKey   string
+		pinBlock string
+	}{
+		{"FFFF9876543210E00001", "042666b49184cf5c68de9628d0397b36", "1B9C1845EB993A7A"},
+		{"FFFF9876543210E00002", "c46551cef9fd244faa9ad834130d3b38", "10A01C8D02C69107"},
+		{"FFFF9876543210E00003", "0df3d9422aca561a47676d07ad6bad05", "18DC07B94797B466"},
+	}
+	pan := cardvalidate.NewPAN("4012345678909")
+	for _, tc := range tests {
+		ksn := mustHex(t, tc.ksn)
+		key, err := d.Key(ksn, PINVariant)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tc.ksn, err)
+		}
+		if have := hex.EncodeToString(key); have != tc.pinKey {
+			t.Errorf("%s: want PIN key %s have %s", tc.ksn, tc.pinKey, have)
+		}
+
+		p, err := d.DecryptPIN(ksn, pin.Format0, mustHex(t, tc.pinBlock), pan)
+		if err != nil || p != "1234" {
+			t.Errorf("%s: DecryptPIN() = %q, %v", tc.ksn, p, err)
+		}
+	}
+
+	key, _ := d.Key(mustHex(t, "FFFF9876543210E00001"), DataVariant)
+	if have := hex.EncodeToString(key); have != "448d3f076d8304036a55a3d7e0055a78" {
+		t.Errorf("unexpected data key: %s", have)
+	}
+}
+
+func TestTDESDecryptTrack(t *testing.T) {
+	d, _ := NewTDES(mustHex(t, "0123456789ABCDEFFEDCBA9876543210"))
+	ksn := mustHex(t, "FFFF9876543210E0000A")
+	key, _ := d.Key(ksn, DataVariant)
+	block, _ := newTripleDES(key)
+
+	tests := []struct {
+		plaintext string
+		name      string
+	}{
+		{";4111111111111111=49121011234567890?", ""},
+		{"%B4111111111111111^DOE/JANE^4912101123456789?", "DOE/JANE"},
+		{"4111111111111111D4912101123456789F", ""},
+	}
+	for _, tc := range tests {
+		data, result, err := DecryptTrack(d, ksn, encryptCBC(t, block, tc.plaintext))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.plaintext, err)
+			continue
+		}
+		if data.PAN != "4111111111111111" || data.Expiry != "4912" || data.Name != tc.name {
+			t.Errorf("%s: unexpected track data: %+v", tc.plaintext, data)
+		}
+		if result.Issuer != issuer.Visa {
+			t.Errorf("%s: unexpected issuer: %s", tc.plaintext, result.Issuer)
+		}
+	}
+}
+
+// ANSI X9.24-3:2017 AES-128 test vectors.
+func TestAES(t *testing.T) {
+	d, err := NewAES(mustHex(t, "FEDCBA9876543210F1F1F1F1F1F1F1F1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ksn := mustHex(t, "123456789012345600000001")
+
+	ik, err := d.InitialKey(ksn)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if have := hex.EncodeToString(ik); have != "1273671ea26ac29afa4d1084127652a1" {
+		t.Errorf("unexpected initial key: %s", have)
+	}
+
+	// The intermediate derivation key for transaction counter 1.
+	intermediate, err := d.derive(ik, d.derivationData(usageKeyDerivation, ksn, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if have := hex.EncodeToString(intermediate); have != "4f21b565bad9835e112b6465635eae44" {
+		t.Errorf("unexpected intermediate key: %s", have)
+	}
+
+	key, err := d.Key(ksn, PINVariant)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if have := hex.EncodeToString(key); have != "af8cb133a78f8dc2d1359f18527593fb" {
+		t.Errorf("unexpected PIN key: %s", have)
+	}
+
+	// Working keys are as long as the base derivation key.
+	for _, size := range []int{16, 24, 32} {
+		d, _ := NewAES(make([]byte, size))
+		if key, err := d.Key(ksn, DataVariant); err != nil || len(key) != size {
+			t.Errorf("%d-byte BDK: unexpected data key: %x, %v", size, key, err)
+		}
+	}
+}
+
+func TestAESDecrypt(t *testing.T) {
+	d, _ := NewAES(mustHex(t, "FEDCBA9876543210F1F1F1F1F1F1F1F1"))
+	ksn := mustHex(t, "123456789012345600000007")
+	pan := cardvalidate.NewPAN("5555555555554444")
+
+	key, _ := d.Key(ksn, DataVariant)
+	block, _ := aes.NewCipher(key)
+	data, result, err := DecryptTrack(d, ksn, encryptCBC(t, block, ";5555555555554444=49122011234?"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if data.PAN != pan.Raw() || data.ServiceCode != "201" || result.Issuer != issuer.MasterCard {
+		t.Errorf("unexpected result: %+v, %+v", data, result)
+	}
+
+	pinKey, _ := d.Key(ksn, PINVariant)
+	pinBlock, err := pin.EncryptFormat4(pinKey, "4321", pan)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if p, err := d.DecryptPIN(ksn, pin.Format4, pinBlock, pan); err != nil || p != "4321" {
+		t.Errorf("DecryptPIN() = %q, %v", p, err)
+	}
+	if _, err := d.DecryptPIN(ksn, pin.Format0, pinBlock, pan); err == nil {
+		t.Error("expected an error for a format 0 PIN block")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := NewTDES(make([]byte, 24)); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("NewTDES(): unexpected error: %v", err)
+	}
+	if _, err := NewAES(make([]byte, 8)); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("NewAES(): unexpected error: %v", err)
+	}
+
+	tdes, _ := NewTDES(mustHex(t, "0123456789ABCDEFFEDCBA9876543210"))
+	aesDukpt, _ := NewAES(mustHex(t, "FEDCBA9876543210F1F1F1F1F1F1F1F1"))
+	for _, tc := range []struct {
+		d   Decrypter
+		ksn []byte
+	}{
+		{tdes, mustHex(t, "FFFF9876543210E00001")},
+		{aesDukpt, mustHex(t, "123456789012345600000001")},
+	} {
+		if _, err := tc.d.DecryptData(tc.ksn[1:], make([]byte, 16)); !errors.Is(err, ErrInvalidKSN) {
+			t.Errorf("%T: short KSN: unexpected error: %v", tc.d, err)
+		}
+		if _, err := tc.d.DecryptData(tc.ksn, make([]byte, 15)); !errors.Is(err, ErrInvalidCiphertext) {
+			t.Errorf("%T: partial block: unexpected error: %v", tc.d, err)
+		}
+		if _, err := tc.d.Key(tc.ksn, Variant(5)); err == nil {
+			t.Errorf("%T: expected an error for an unknown variant", tc.d)
+		}
+	}
+
+	ksn := mustHex(t, "FFFF9876543210E00001")
+	key, _ := tdes.Key(ksn, DataVariant)
+	block, _ := newTripleDES(key)
+	if _, _, err := DecryptTrack(tdes, ksn, encryptCBC(t, block, "not track data")); !errors.Is(err, track.ErrMalformedTrack) {
+		t.Errorf("garbage: unexpected error: %v", err)
+	}
+	data, _, err := DecryptTrack(tdes, ksn, encryptCBC(t, block, ";4111111111111111=15121011234?"))
+	if !errors.Is(err, cardvalidate.ErrCardExpired) || data.PAN != "4111111111111111" {
+		t.Errorf("expired card: unexpected result: %+v, %v", data, err)
+	}
+}
@@ -0,0 +1,173 @@
+package dukpt
+
+import (
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/pin"
+)
+
+// TDES key serial numbers are 10 bytes, the last 21 bits of which are the transaction counter.
+const (
+	tdesKSNSize     = 10
+	tdesCounterBits = 21
+)
+
+var (
+	// keyMask is XORed into the right key of the IPEK and the left key of each transaction key.
+	keyMask = []byte{0xc0, 0xc0, 0xc0, 0xc0, 0, 0, 0, 0, 0xc0, 0xc0, 0xc0, 0xc0, 0, 0, 0, 0}
+
+	// Variant masks of the transaction keys.
+	tdesVariants = map[Variant][]byte{
+		PINVariant:  {0, 0, 0, 0, 0, 0, 0, 0xff, 0, 0, 0, 0, 0, 0, 0, 0xff},
+		DataVariant: {0, 0, 0, 0, 0, 0xff, 0, 0, 0, 0, 0, 0, 0, 0xff, 0, 0},
+	}
+)
+
+// TDES derives TDES DUKPT keys from a double-length base derivation key.
+type TDES struct {
+	bdk []byte
+}
+
+// NewTDES returns a TDES DUKPT decrypter with 16-byte base derivation key bdk.
+func NewTDES(bdk []byte) (*TDES, error) {
+	if len(bdk) != 2*des.BlockSize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKey, 2*des.BlockSize, len(bdk))
+	}
+	return &TDES{bdk: append([]byte(nil), bdk...)}, nil
+}
+
+// IPEK returns the initial PIN encryption key loaded into the reader with key serial number ksn.
+func (d *TDES) IPEK(ksn []byte) ([]byte, error) {
+	if len(ksn) != tdesKSNSize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKSN, tdesKSNSize, len(ksn))
+	}
+
+	// The leftmost 8 bytes of the KSN with the counter cleared.
+	data := make([]byte, des.BlockSize)
+	copy(data, ksn)
+	data[7] &^= 0x1f
+
+	ipek := make([]byte, 2*des.BlockSize)
+	if err := encryptTDES(d.bdk, ipek[:8], data); err != nil {
+		return nil, err
+	}
+	masked := make([]byte, len(d.bdk))
+	subtle.XORBytes(masked, d.bdk, keyMask)
+	if err := encryptTDES(masked, ipek[8:], data); err != nil {
+		return nil, err
+	}
+	return ipek, nil
+}
+
+// Key returns the transaction key of variant v for key serial number ksn.
+func (d *TDES) Key(ksn []byte, v Variant) ([]byte, error) {
+	mask, ok := tdesVariants[v]
+	if !ok {
+		return nil, fmt.Errorf("dukpt: unsupported variant %s", v)
+	}
+	key, err := d.IPEK(ksn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk the counter's bits from the top, deriving a key for each one that is set.
+	reg := binary.BigEndian.Uint64(ksn[2:])
+	counter := reg & (1<<tdesCounterBits - 1)
+	reg &^= counter
+	for bit := uint64(1) << (tdesCounterBits - 1); bit > 0; bit >>= 1 {
+		if counter&bit != 0 {
+			reg |= bit
+			key = nonReversibleKey(key, binary.BigEndian.AppendUint64(nil, reg))
+		}
+	}
+
+	subtle.XORBytes(key, key, mask)
+	if v == DataVariant {
+		// The data key is the variant key encrypted with itself.
+		owf := make([]byte, len(key))
+		if err := encryptTDES(key, owf[:8], key[:8]); err != nil {
+			return nil, err
+		}
+		if err := encryptTDES(key, owf[8:], key[8:]); err != nil {
+			return nil, err
+		}
+		key = owf
+	}
+	return key, nil
+}
+
+// DecryptData decrypts card data encrypted in CBC mode with a zero IV under the data key.
+func (d *TDES) DecryptData(ksn, ciphertext []byte) ([]byte, error) {
+	block, err := d.cipher(ksn, DataVariant)
+	if err != nil {
+		return nil, err
+	}
+	return decryptCBC(block, ciphertext)
+}
+
+// DecryptPIN returns the PIN from a format 0, 1 or 3 PIN block encrypted under the PIN key.
+func (d *TDES) DecryptPIN(ksn []byte, f pin.Format, pinBlock []byte, pan cardvalidate.PAN) (string, error) {
+	if len(pinBlock) != des.BlockSize {
+		return "", fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidCiphertext, des.BlockSize, len(pinBlock))
+	}
+	block, err := d.cipher(ksn, PINVariant)
+	if err != nil {
+		return "", err
+	}
+
+	clearBlock := make([]byte, des.BlockSize)
+	block.Decrypt(clearBlock, pinBlock)
+	return pin.Decode(f, clearBlock, pan)
+}
+
+// cipher returns a TDES cipher with the transaction key of variant v for ksn.
+func (d *TDES) cipher(ksn []byte, v Variant) (cipher.Block, error) {
+	key, err := d.Key(ksn, v)
+	if err != nil {
+		return nil, err
+	}
+	return newTripleDES(key)
+}
+
+// nonReversibleKey derives the next transaction key from key and the KSN register reg.
+func nonReversibleKey(key, reg []byte) []byte {
+	next := make([]byte, 2*des.BlockSize)
+	encryptHalf(next[8:], key, reg)
+
+	masked := make([]byte, len(key))
+	subtle.XORBytes(masked, key, keyMask)
+	encryptHalf(next[:8], masked, reg)
+	return next
+}
+
+// encryptHalf sets dst to DES(left key, reg XOR right key) XOR right key.
+func encryptHalf(dst, key, reg []byte) {
+	block, _ := des.NewCipher(key[:8]) // Can't fail with an 8-byte key.
+	subtle.XORBytes(dst, reg, key[8:])
+	block.Encrypt(dst, dst)
+	subtle.XORBytes(dst, dst, key[8:])
+}
+
+// encryptTDES encrypts a single block src into dst with double-length key.
+func encryptTDES(key, dst, src []byte) error {
+	block, err := newTripleDES(key)
+	if err != nil {
+		return err
+	}
+	block.Encrypt(dst, src)
+	return nil
+}
+
+// newTripleDES returns a TDES cipher with a double-length key.
+func newTripleDES(key []byte) (cipher.Block, error) {
+	block, err := des.NewTripleDESCipher(append(append([]byte(nil), key...), key[:8]...))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	return block, nil
+}
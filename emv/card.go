@@ -0,0 +1,104 @@
+package emv
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/track"
+)
+
+var (
+	ErrMissingTag   = errors.New("emv: missing tag")
+	ErrDataMismatch = errors.New("emv: track 2 equivalent data doesn't match card data")
+)
+
+// CardData holds card information extracted from EMV data objects.
+type CardData struct {
+	PAN               string      // Tag 5A.
+	Expiry            string      // Tag 5F24 in YYMMDD format.
+	Name              string      // Tag 5F20.
+	PANSequenceNumber string      // Tag 5F34.
+	Track2            *track.Data // Tag 57.
+}
+
+// Extract extracts card information from decoded data objects. Either the PAN (5A) and
+// expiration date (5F24), or track 2 equivalent data (57) have to be present.
+func Extract(tlvs []TLV) (CardData, error) {
+	var card CardData
+
+	if tlv, ok := Find(tlvs, TagPAN); ok {
+		card.PAN = strings.TrimRight(strings.ToUpper(hex.EncodeToString(tlv.Value)), "F")
+	}
+	if tlv, ok := Find(tlvs, TagExpirationDate); ok {
+		card.Expiry = hex.EncodeToString(tlv.Value)
+	}
+	if tlv, ok := Find(tlvs, TagCardholderName); ok {
+		card.Name = strings.TrimSpace(string(tlv.Value))
+	}
+	if tlv, ok := Find(tlvs, TagPANSequenceNumber); ok {
+		card.PANSequenceNumber = hex.EncodeToString(tlv.Value)
+	}
+	if tlv, ok := Find(tlvs, TagTrack2Equivalent); ok {
+		t2, err := track.ParseTrack2Equivalent(hex.EncodeToString(tlv.Value))
+		if err != nil {
+			return CardData{}, fmt.Errorf("tag %s: %w", TagTrack2Equivalent, err)
+		}
+		card.Track2 = &t2
+	}
+
+	if card.Track2 == nil {
+		if card.PAN == "" {
+			return CardData{}, fmt.Errorf("%w: %s", ErrMissingTag, TagPAN)
+		}
+		if card.Expiry == "" {
+			return CardData{}, fmt.Errorf("%w: %s", ErrMissingTag, TagExpirationDate)
+		}
+	}
+	if card.Expiry != "" && (len(card.Expiry) != 6 || !isDigits(card.Expiry)) {
+		return CardData{}, fmt.Errorf("%w: invalid expiration date %s", ErrMalformedTLV, card.Expiry)
+	}
+	return card, nil
+}
+
+// ExpirationDate returns the expiration date in the MM/YYYY format used by cardvalidate, or an
+// empty string if there is no well-formed one.
+func (c CardData) ExpirationDate() string {
+	if c.Expiry == "" && c.Track2 != nil {
+		return c.Track2.ExpirationDate()
+	}
+	if len(c.Expiry) != 6 {
+		return ""
+	}
+	return c.Expiry[2:4] + "/20" + c.Expiry[:2]
+}
+
+// Validate cross-checks track 2 equivalent data against the PAN and expiration date if both
+// are present and validates the card with cardvalidate.
+func (c CardData) Validate() (cardvalidate.Result, error) {
+	pan := c.PAN
+	if c.Track2 != nil {
+		if pan == "" {
+			pan = c.Track2.PAN
+		}
+		if c.Track2.PAN != pan {
+			return cardvalidate.Result{}, fmt.Errorf("%w: PAN", ErrDataMismatch)
+		}
+		if c.Expiry != "" && c.Track2.ExpirationDate() != c.ExpirationDate() {
+			return cardvalidate.Result{}, fmt.Errorf("%w: expiration date", ErrDataMismatch)
+		}
+	}
+	return cardvalidate.ValidateCard(pan, c.ExpirationDate())
+}
+
+// isDigits checks if s consists of ASCII digits only.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package emv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate/track"
+)
+
+// tlv encodes a single BER-TLV data object from hex-encoded tag and raw value.
+func tlv(t *testing.T, tag string, value []byte) []byte {
+	b, err := hex.DecodeString(tag)
+	if err != nil {
+		t.Fatalf("invalid tag %s: %s", tag, err)
+	}
+	switch n := len(value); {
+	case n < 0x80:
+		b = append(b, byte(n))
+	case n <= 0xFF:
+		b = append(b, 0x81, byte(n))
+	default:
+		b = append(b, 0x82, byte(n>>8), byte(n))
+	}
+	return append(b, value...)
+}
+
+// hexBytes decodes s or fails the test.
+func hexBytes(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex %s: %s", s, err)
+	}
+	return b
+}
+
+// readRecord returns a READ RECORD response with given track 2 equivalent data and PAN.
+func readRecord(t *testing.T, track2, pan string) []byte {
+	var record []byte
+	record = append(record, tlv(t, "57", hexBytes(t, track2))...)
+	record = append(record, tlv(t, "5A", hexBytes(t, pan))...)
+	record = append(record, tlv(t, "5F20", []byte("DOE/JOHN"))...)
+	record = append(record, tlv(t, "5F24", hexBytes(t, "281231"))...)
+	record = append(record, tlv(t, "5F34", hexBytes(t, "01"))...)
+	return tlv(t, "70", record)
+}
+
+func TestDecode(t *testing.T) {
+	long := bytes.Repeat([]byte{0xAB}, 300)
+	data := append(tlv(t, "6F", append(tlv(t, "84", []byte{0xA0, 0x00}), tlv(t, "BF0C", tlv(t, "9F10", long))...)), 0x00, 0x00)
+
+	tlvs, err := Decode(data)
+	if err != nil {
+		t.Fatalf("unexpected decoding error: %s", err)
+	}
+	if len(tlvs) != 1 || tlvs[0].Tag != TagFCITemplate || len(tlvs[0].Children) != 2 {
+		t.Fatalf("unexpected data objects: %+v", tlvs)
+	}
+
+	iad, ok := Find(tlvs, TagIssuerApplicationData)
+	if !ok || !bytes.Equal(iad.Value, long) {
+		t.Fatalf("nested multi-byte tag with long length wasn't decoded")
+	}
+	if !TagFCIIssuerDiscretional.Constructed() || TagIssuerApplicationData.Constructed() {
+		t.Fatalf("unexpected constructed flags")
+	}
+	if TagPAN.Name() == "" || TagPAN.String() != "5A" {
+		t.Fatalf("unexpected tag name: %s %s", TagPAN, TagPAN.Name())
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	tests := []string{
+		"5A",           // Missing length.
+		"5A0841",       // Value is shorter than length.
+		"9F",           // Truncated tag.
+		"9F8181818101", // Tag is longer than 4 bytes.
+		"5A80",         // Indefinite length.
+		"5A84FFFFFFFF", // Length is too long.
+		"70035A0241",   // Malformed nested object.
+		"zz",
+	}
+
+	for _, tc := range tests {
+		if _, err := DecodeHex(tc); !errors.Is(err, ErrMalformedTLV) {
+			t.Errorf("expected ErrMalformedTLV for %s, got %v", tc, err)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tlvs, err := Decode(readRecord(t, "4111111111111111D281220112345F", "4111111111111111"))
+	if err != nil {
+		t.Fatalf("unexpected decoding error: %s", err)
+	}
+
+	card, err := Extract(tlvs)
+	if err != nil {
+		t.Fatalf("unexpected extraction error: %s", err)
+	}
+	if card.PAN != "4111111111111111" || card.Name != "DOE/JOHN" || card.Expiry != "281231" ||
+		card.PANSequenceNumber != "01" || card.Track2 == nil || card.Track2.ServiceCode != "201" {
+		t.Fatalf("unexpected card data: %+v", card)
+	}
+	if card.ExpirationDate() != "12/2028" {
+		t.Fatalf("unexpected expiration date: %s", card.ExpirationDate())
+	}
+
+	if _, err := card.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+}
+
+func TestExtractMismatch(t *testing.T) {
+	tests := []struct {
+		track2 string
+		pan    string
+	}{
+		{"4012888888881881D281220112345F", "4111111111111111"},
+		{"4111111111111111D271220112345F", "4111111111111111"},
+	}
+
+	for _, tc := range tests {
+		tlvs, err := Decode(readRecord(t, tc.track2, tc.pan))
+		if err != nil {
+			t.Fatalf("unexpected decoding error: %s", err)
+		}
+		card, err := Extract(tlvs)
+		if err != nil {
+			t.Fatalf("unexpected extraction error: %s", err)
+		}
+		if _, err := card.Validate(); !errors.Is(err, ErrDataMismatch) {
+			t.Errorf("expected ErrDataMismatch for %s/%s, got %v", tc.track2, tc.pan, err)
+		}
+	}
+
+	if _, err := Extract(nil); !errors.Is(err, ErrMissingTag) {
+		t.Errorf("expected ErrMissingTag, got %v", err)
+	}
+}
+
+func TestMalformedExpiry(t *testing.T) {
+	for _, card := range []CardData{
+		{PAN: "4111111111111111"},
+		{PAN: "4111111111111111", Expiry: "2812"},
+		{PAN: "4111111111111111", Track2: &track.Data{PAN: "4111111111111111", Expiry: "28"}},
+	} {
+		if date := card.ExpirationDate(); date != "" {
+			t.Errorf("%+v: unexpected expiration date %s", card, date)
+		}
+	}
+}
+
+func TestValidateMalformedExpiry(t *testing.T) {
+	card := CardData{
+		PAN:    "4111111111111111",
+		Expiry: "28",
+		Track2: &track.Data{PAN: "4111111111111111", Expiry: "2812"},
+	}
+	if _, err := card.Validate(); !errors.Is(err, ErrDataMismatch) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package emv decodes BER-TLV encoded EMV chip card data and extracts card information from it.
+package emv
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMalformedTLV is returned when BER-TLV data can't be decoded.
+var ErrMalformedTLV = errors.New("emv: malformed BER-TLV data")
+
+// Tag is a BER-TLV tag with its bytes stored big-endian, e.g. 0x5F24.
+type Tag uint32
+
+// Common EMV tags.
+const (
+	TagAID                   Tag = 0x4F
+	TagApplicationLabel      Tag = 0x50
+	TagTrack2Equivalent      Tag = 0x57
+	TagPAN                   Tag = 0x5A
+	TagCardholderName        Tag = 0x5F20
+	TagExpirationDate        Tag = 0x5F24
+	TagEffectiveDate         Tag = 0x5F25
+	TagIssuerCountryCode     Tag = 0x5F28
+	TagLanguagePreference    Tag = 0x5F2D
+	TagPANSequenceNumber     Tag = 0x5F34
+	TagFCITemplate           Tag = 0x6F
+	TagRecordTemplate        Tag = 0x70
+	TagResponseTemplate2     Tag = 0x77
+	TagAIP                   Tag = 0x82
+	TagDFName                Tag = 0x84
+	TagApplicationPriority   Tag = 0x87
+	TagCDOL1                 Tag = 0x8C
+	TagCDOL2                 Tag = 0x8D
+	TagCVMList               Tag = 0x8E
+	TagAFL                   Tag = 0x94
+	TagFCIProprietary        Tag = 0xA5
+	TagApplicationUsage      Tag = 0x9F07
+	TagApplicationVersion    Tag = 0x9F08
+	TagIssuerApplicationData Tag = 0x9F10
+	TagTrack1Discretionary   Tag = 0x9F1F
+	TagApplicationCryptogram Tag = 0x9F26
+	TagCryptogramInfo        Tag = 0x9F27
+	TagATC                   Tag = 0x9F36
+	TagPDOL                  Tag = 0x9F38
+	TagFCIIssuerDiscretional Tag = 0xBF0C
+)
+
+// tagNames maps known tags to their names from EMV Book 3.
+var tagNames = map[Tag]string{
+	TagAID:                   "Application Identifier (AID)",
+	TagApplicationLabel:      "Application Label",
+	TagTrack2Equivalent:      "Track 2 Equivalent Data",
+	TagPAN:                   "Application Primary Account Number (PAN)",
+	TagCardholderName:        "Cardholder Name",
+	TagExpirationDate:        "Application Expiration Date",
+	TagEffectiveDate:         "Application Effective Date",
+	TagIssuerCountryCode:     "Issuer Country Code",
+	TagLanguagePreference:    "Language Preference",
+	TagPANSequenceNumber:     "Application PAN Sequence Number",
+	TagFCITemplate:           "File Control Information (FCI) Template",
+	TagRecordTemplate:        "READ RECORD Response Message Template",
+	TagResponseTemplate2:     "Response Message Template Format 2",
+	TagAIP:                   "Application Interchange Profile",
+	TagDFName:                "Dedicated File (DF) Name",
+	TagApplicationPriority:   "Application Priority Indicator",
+	TagCDOL1:                 "Card Risk Management Data Object List 1 (CDOL1)",
+	TagCDOL2:                 "Card Risk Management Data Object List 2 (CDOL2)",
+	TagCVMList:               "Cardholder Verification Method (CVM) List",
+	TagAFL:                   "Application File Locator (AFL)",
+	TagFCIProprietary:        "File Control Information (FCI) Proprietary Template",
+	TagApplicationUsage:      "Application Usage Control",
+	TagApplicationVersion:    "Application Version Number",
+	TagIssuerApplicationData: "Issuer Application Data",
+	TagTrack1Discretionary:   "Track 1 Discretionary Data",
+	TagApplicationCryptogram: "Application Cryptogram",
+	TagCryptogramInfo:        "Cryptogram Information Data",
+	TagATC:                   "Application Transaction Counter (ATC)",
+	TagPDOL:                  "Processing Options Data Object List (PDOL)",
+	TagFCIIssuerDiscretional: "File Control Information (FCI) Issuer Discretionary Data",
+}
+
+// String implements fmt.Stringer
+func (t Tag) String() string {
+	return fmt.Sprintf("%X", uint32(t))
+}
+
+// Name returns the tag's name or an empty string if the tag is unknown.
+func (t Tag) Name() string {
+	return tagNames[t]
+}
+
+// Constructed checks if the tag's value is made of nested TLVs.
+func (t Tag) Constructed() bool {
+	first := uint32(t)
+	for first > 0xFF {
+		first >>= 8
+	}
+	return first&0x20 != 0
+}
+
+// TLV is a decoded BER-TLV data object. Children are set for constructed tags.
+type TLV struct {
+	Tag      Tag
+	Value    []byte
+	Children []TLV
+}
+
+// Decode decodes a stream of BER-TLV data objects. Constructed objects are decoded
+// recursively, and 0x00 and 0xFF padding bytes between objects are skipped.
+func Decode(data []byte) ([]TLV, error) {
+	var tlvs []TLV
+	for len(data) > 0 {
+		if data[0] == 0x00 || data[0] == 0xFF {
+			data = data[1:]
+			continue
+		}
+
+		tag, n, err := decodeTag(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+
+		length, n, err := decodeLength(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[n:]
+
+		if length > len(data) {
+			return nil, fmt.Errorf("%w: tag %s length %d exceeds remaining %d bytes", ErrMalformedTLV, tag, length, len(data))
+		}
+
+		tlv := TLV{Tag: tag, Value: data[:length]}
+		if tag.Constructed() {
+			if tlv.Children, err = Decode(tlv.Value); err != nil {
+				return nil, err
+			}
+		}
+		tlvs = append(tlvs, tlv)
+		data = data[length:]
+	}
+	return tlvs, nil
+}
+
+// DecodeHex is like Decode but takes hex-encoded data. Whitespace is ignored.
+func DecodeHex(s string) ([]TLV, error) {
+	data, err := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedTLV, err)
+	}
+	return Decode(data)
+}
+
+// decodeTag decodes a possibly multi-byte tag and returns the number of bytes it took.
+func decodeTag(data []byte) (Tag, int, error) {
+	tag := Tag(data[0])
+	if data[0]&0x1F != 0x1F {
+		return tag, 1, nil
+	}
+
+	for i := 1; i < len(data); i++ {
+		if i > 3 {
+			return 0, 0, fmt.Errorf("%w: tag is longer than 4 bytes", ErrMalformedTLV)
+		}
+		tag = tag<<8 | Tag(data[i])
+		if data[i]&0x80 == 0 {
+			return tag, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: truncated tag", ErrMalformedTLV)
+}
+
+// decodeLength decodes a short or long form length and returns the number of bytes it took.
+func decodeLength(data []byte) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, fmt.Errorf("%w: missing length", ErrMalformedTLV)
+	}
+	if data[0] < 0x80 {
+		return int(data[0]), 1, nil
+	}
+
+	n := int(data[0] & 0x7F)
+	if n == 0 {
+		return 0, 0, fmt.Errorf("%w: indefinite length is not supported", ErrMalformedTLV)
+	}
+	if n > 3 {
+		return 0, 0, fmt.Errorf("%w: length is longer than 3 bytes", ErrMalformedTLV)
+	}
+	if len(data) < n+1 {
+		return 0, 0, fmt.Errorf("%w: truncated length", ErrMalformedTLV)
+	}
+
+	length := 0
+	for _, b := range data[1 : n+1] {
+		length = length<<8 | int(b)
+	}
+	return length, n + 1, nil
+}
+
+// Find returns the first data object with given tag, searching nested objects depth-first.
+func Find(tlvs []TLV, tag Tag) (TLV, bool) {
+	for _, tlv := range tlvs {
+		if tlv.Tag == tag {
+			return tlv, true
+		}
+		if found, ok := Find(tlv.Children, tag); ok {
+			return found, true
+		}
+	}
+	return TLV{}, false
+}
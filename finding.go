@@ -0,0 +1,75 @@
+package cardvalidate
+
+import (
+	"fmt"
+	"time"
+)
+
+// Severity of a validation finding.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+// String implements fmt.Stringer
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("Severity(%d)", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *Severity) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "info":
+		*s = SeverityInfo
+	case "warning":
+		*s = SeverityWarning
+	case "error":
+		*s = SeverityError
+	default:
+		return fmt.Errorf("cardvalidate: unknown severity %q", text)
+	}
+	return nil
+}
+
+// Stable finding codes.
+const (
+	// CodeUnknownIIN is reported in lenient mode for card numbers with an unknown IIN.
+	CodeUnknownIIN = "unknown_iin"
+
+	// CodeExpiresSoon is reported for cards that expire within ExpiresSoonPeriod.
+	CodeExpiresSoon = "expires_soon"
+
+	// CodeUnusualLength is reported for card numbers whose length is valid but uncommon
+	// for their issuer.
+	CodeUnusualLength = "unusual_length"
+)
+
+// ExpiresSoonPeriod is how close to its expiration date a card has to be for
+// CodeExpiresSoon to be reported.
+const ExpiresSoonPeriod = 30 * 24 * time.Hour
+
+// Finding is an advisory signal found during validation. Unlike hard validation errors,
+// findings don't make a card invalid on their own.
+type Finding struct {
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Score    float64  `json:"score,omitempty"` // Confidence of heuristic findings, from 0 to 1.
+}
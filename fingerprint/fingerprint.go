@@ -0,0 +1,172 @@
+// Package fingerprint computes keyed card number fingerprints, so that requests made with the
+// same card can be correlated without storing the card number.
+package fingerprint
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+var (
+	ErrInvalidKeyring = errors.New("fingerprint: invalid keyring")
+	ErrUnknownKey     = errors.New("fingerprint: unknown key ID")
+)
+
+// MinKeySize is the smallest accepted secret key size in bytes.
+const MinKeySize = 32
+
+// Fingerprint is an HMAC-SHA256 of a card number.
+type Fingerprint struct {
+	KeyID string `json:"key_id"` // ID of the key the fingerprint was computed with.
+	Value string `json:"value"`  // Hex-encoded MAC.
+}
+
+// Key is a secret fingerprinting key.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Keyring holds an active key that new fingerprints are computed with and older keys that are
+// still accepted by Verify, so keys can be rotated without invalidating stored fingerprints.
+// It is safe for concurrent use.
+type Keyring struct {
+	active string
+	keys   map[string][]byte
+}
+
+// NewKeyring returns a keyring with keys, where active is the ID of the key used for new
+// fingerprints.
+func NewKeyring(active string, keys ...Key) (*Keyring, error) {
+	k := &Keyring{
+		active: active,
+		keys:   make(map[string][]byte, len(keys)),
+	}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("%w: missing key ID", ErrInvalidKeyring)
+		}
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate key ID %q", ErrInvalidKeyring, key.ID)
+		}
+		if len(key.Secret) < MinKeySize {
+			return nil, fmt.Errorf("%w: key %q is shorter than %d bytes", ErrInvalidKeyring, key.ID, MinKeySize)
+		}
+		k.keys[key.ID] = append([]byte(nil), key.Secret...)
+	}
+	if _, ok := k.keys[active]; !ok {
+		return nil, fmt.Errorf("%w: active key %q not found", ErrInvalidKeyring, active)
+	}
+	return k, nil
+}
+
+// ActiveKeyID returns the ID of the key used for new fingerprints.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Fingerprint computes pan's fingerprint with the active key.
+func (k *Keyring) Fingerprint(pan cardvalidate.PAN) Fingerprint {
+	return Fingerprint{
+		KeyID: k.active,
+		Value: hex.EncodeToString(mac(k.keys[k.active], pan)),
+	}
+}
+
+// FingerprintWith computes pan's fingerprint with the key keyID, e.g. to look up records
+// fingerprinted before the last rotation.
+func (k *Keyring) FingerprintWith(keyID string, pan cardvalidate.PAN) (Fingerprint, error) {
+	secret, ok := k.keys[keyID]
+	if !ok {
+		return Fingerprint{}, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	return Fingerprint{KeyID: keyID, Value: hex.EncodeToString(mac(secret, pan))}, nil
+}
+
+// Verify checks if fp is pan's fingerprint. Fingerprints made with any key in the keyring
+// are accepted.
+func (k *Keyring) Verify(pan cardvalidate.PAN, fp Fingerprint) bool {
+	secret, ok := k.keys[fp.KeyID]
+	if !ok {
+		return false
+	}
+	value, err := hex.DecodeString(fp.Value)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(value, mac(secret, pan))
+}
+
+// mac computes HMAC-SHA256 of pan's digits with secret.
+func mac(secret []byte, pan cardvalidate.PAN) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write(pan.Bytes())
+	return h.Sum(nil)
+}
+
+// keyringFile is the JSON format of a keyring file.
+type keyringFile struct {
+	Active string            `json:"active"`
+	Keys   map[string]string `json:"keys"` // Base64-encoded secrets by key ID.
+}
+
+// LoadKeyring loads a keyring from a JSON file at path, e.g.:
+//
+//	{"active": "2025-01", "keys": {"2025-01": "<base64 secret>", "2024-01": "<base64 secret>"}}
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKeyring, err)
+	}
+
+	keys := make([]Key, 0, len(f.Keys))
+	for id, secret := range f.Keys {
+		key, err := decodeKey(id, secret)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyring(f.Active, keys...)
+}
+
+// ParseKeyring parses a keyring from a comma-separated list of id:secret pairs with
+// base64-encoded secrets, as found in environment variables. The first key is the active one.
+func ParseKeyring(s string) (*Keyring, error) {
+	var keys []Key
+	for _, pair := range strings.Split(s, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: expected id:secret pairs", ErrInvalidKeyring)
+		}
+		key, err := decodeKey(id, secret)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyring(keys[0].ID, keys...)
+}
+
+// decodeKey decodes a base64-encoded secret of the key id.
+func decodeKey(id, secret string) (Key, error) {
+	b, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return Key{}, fmt.Errorf("%w: key %q: %s", ErrInvalidKeyring, id, err)
+	}
+	return Key{ID: id, Secret: b}, nil
+}
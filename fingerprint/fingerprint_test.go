@@ -0,0 +1,116 @@
+package fingerprint
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+var (
+	secret1 = bytes.Repeat([]byte{0x01}, 32)
+	secret2 = bytes.Repeat([]byte{0x02}, 32)
+)
+
+func TestKeyring(t *testing.T) {
+	old, err := NewKeyring("k1", Key{ID: "k1", Secret: secret1})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	rotated, err := NewKeyring("k2", Key{ID: "k1", Secret: secret1}, Key{ID: "k2", Secret: secret2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	pan := cardvalidate.NewPAN("4111111111111111")
+	fp := old.Fingerprint(pan)
+	if fp.KeyID != "k1" || len(fp.Value) != 64 {
+		t.Fatalf("unexpected fingerprint: %+v", fp)
+	}
+	if again := old.Fingerprint(cardvalidate.NewPAN("4111111111111111")); again != fp {
+		t.Errorf("fingerprints of the same card differ: %+v, %+v", fp, again)
+	}
+	if other := old.Fingerprint(cardvalidate.NewPAN("5555555555554444")); other.Value == fp.Value {
+		t.Error("fingerprints of different cards are equal")
+	}
+
+	// Fingerprints made before the rotation are still verified.
+	if !rotated.Verify(pan, fp) {
+		t.Error("expected an old fingerprint to verify after rotation")
+	}
+	if nfp := rotated.Fingerprint(pan); nfp.KeyID != "k2" || nfp.Value == fp.Value {
+		t.Errorf("expected a fingerprint with the new key, got %+v", nfp)
+	}
+	if got, err := rotated.FingerprintWith("k1", pan); err != nil || got != fp {
+		t.Errorf("FingerprintWith(k1) = %+v, %v", got, err)
+	}
+	if _, err := rotated.FingerprintWith("k3", pan); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+
+	if rotated.Verify(cardvalidate.NewPAN("5555555555554444"), fp) {
+		t.Error("fingerprint verified for a different card")
+	}
+	if old.Verify(pan, Fingerprint{KeyID: "k2", Value: fp.Value}) {
+		t.Error("fingerprint verified with an unknown key")
+	}
+}
+
+func TestNewKeyringErrors(t *testing.T) {
+	cases := []struct {
+		active string
+		keys   []Key
+	}{
+		{"k1", nil},
+		{"k1", []Key{{ID: "k1", Secret: []byte("short")}}},
+		{"k1", []Key{{ID: "", Secret: secret1}}},
+		{"k1", []Key{{ID: "k1", Secret: secret1}, {ID: "k1", Secret: secret2}}},
+	}
+	for i, c := range cases {
+		if _, err := NewKeyring(c.active, c.keys...); !errors.Is(err, ErrInvalidKeyring) {
+			t.Errorf("case %d: expected ErrInvalidKeyring, got %v", i, err)
+		}
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data := `{"active": "k2", "keys": {"k1": "` + base64.StdEncoding.EncodeToString(secret1) +
+		`", "k2": "` + base64.StdEncoding.EncodeToString(secret2) + `"}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if k.ActiveKeyID() != "k2" {
+		t.Errorf("unexpected active key: %s", k.ActiveKeyID())
+	}
+
+	if _, err := LoadKeyring(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	s := "k2:" + base64.StdEncoding.EncodeToString(secret2) + ", k1:" + base64.StdEncoding.EncodeToString(secret1)
+	k, err := ParseKeyring(s)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if k.ActiveKeyID() != "k2" {
+		t.Errorf("unexpected active key: %s", k.ActiveKeyID())
+	}
+
+	for _, s := range []string{"", "k1", "k1:not base64!"} {
+		if _, err := ParseKeyring(s); !errors.Is(err, ErrInvalidKeyring) {
+			t.Errorf("ParseKeyring(%q): expected ErrInvalidKeyring, got %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package fpe
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/internal/luhn"
+)
+
+var ErrInvalidPAN = errors.New("fpe: invalid card number")
+
+// PANOptions control which parts of a card number are kept as is by EncryptPAN.
+type PANOptions struct {
+	KeepBIN   bool // Keep the first 6 digits so the ciphertext has the same issuer.
+	KeepLast4 bool // Keep the last 4 digits.
+
+	// FixLuhn makes the ciphertext pass Luhn's check. The card number has to pass it as well.
+	FixLuhn bool
+}
+
+// EncryptPAN encrypts the digits of pan that aren't kept according to opts, which need at
+// least 6 digits left, e.g. keeping both the BIN and the last 4 digits only works with cards
+// of 16 digits or more.
+//
+// With FixLuhn the digits are encrypted repeatedly until the whole number passes Luhn's check,
+// which keeps the mapping reversible without touching the kept digits.
+func EncryptPAN(c Cipher, pan cardvalidate.PAN, opts PANOptions) (cardvalidate.PAN, error) {
+	return cryptPAN(c.Encrypt, pan, opts)
+}
+
+// DecryptPAN decrypts a card number encrypted by EncryptPAN with the same options.
+func DecryptPAN(c Cipher, pan cardvalidate.PAN, opts PANOptions) (cardvalidate.PAN, error) {
+	return cryptPAN(c.Decrypt, pan, opts)
+}
+
+// cryptPAN applies crypt to the digits of pan that aren't kept according to opts.
+func cryptPAN(crypt func(string) (string, error), pan cardvalidate.PAN, opts PANOptions) (cardvalidate.PAN, error) {
+	digits := pan.Raw()
+	if len(digits) < 8 || len(digits) > 19 {
+		return cardvalidate.PAN{}, fmt.Errorf("%w: must have 8 to 19 digits", ErrInvalidPAN)
+	}
+	if err := checkInput(digits, len(digits)); err == ErrInvalidNumeral {
+		return cardvalidate.PAN{}, fmt.Errorf("%w: must only contain digits", ErrInvalidPAN)
+	}
+	if opts.FixLuhn && !luhn.Valid(digits) {
+		return cardvalidate.PAN{}, fmt.Errorf("%w: doesn't pass Luhn's check", ErrInvalidPAN)
+	}
+
+	start, end := 0, len(digits)
+	if opts.KeepBIN {
+		start = 6
+	}
+	if opts.KeepLast4 {
+		end -= 4
+	}
+	if end-start < minLen {
+		return cardvalidate.PAN{}, fmt.Errorf("%w: fewer than %d digits left to encrypt", ErrInvalidLength, minLen)
+	}
+
+	head, mid, tail := digits[:start], digits[start:end], digits[end:]
+	for {
+		var err error
+		if mid, err = crypt(mid); err != nil {
+			return cardvalidate.PAN{}, err
+		}
+		// Cycle-walk until the result is in the domain of Luhn-valid numbers.
+		if !opts.FixLuhn || luhn.Valid(head+mid+tail) {
+			return cardvalidate.NewPAN(head + mid + tail), nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package fpe implements NIST SP 800-38G format-preserving encryption of decimal strings with
+// FF1 and FF3-1, and uses it to turn card numbers into PAN-shaped ciphertexts.
+package fpe
+
+import (
+	"crypto/cipher"
+	"errors"
+	"math/big"
+)
+
+var (
+	ErrInvalidKey     = errors.New("fpe: invalid key")
+	ErrInvalidTweak   = errors.New("fpe: invalid tweak")
+	ErrInvalidLength  = errors.New("fpe: invalid input length")
+	ErrInvalidNumeral = errors.New("fpe: input is not a decimal string")
+)
+
+// radix is the alphabet size, only decimal digits are supported.
+const radix = 10
+
+// minLen is the shortest input length, the domain must hold at least a million values.
+const minLen = 6
+
+// Cipher encrypts and decrypts decimal strings preserving their length.
+type Cipher interface {
+	Encrypt(x string) (string, error)
+	Decrypt(x string) (string, error)
+}
+
+// checkInput checks that x is a decimal string with length within [minLen, maxLen].
+func checkInput(x string, maxLen int) error {
+	if len(x) < minLen || len(x) > maxLen {
+		return ErrInvalidLength
+	}
+	for i := 0; i < len(x); i++ {
+		if x[i] < '0' || x[i] > '9' {
+			return ErrInvalidNumeral
+		}
+	}
+	return nil
+}
+
+// num returns the number represented by decimal string x.
+func num(x string) *big.Int {
+	n, _ := new(big.Int).SetString(x, radix)
+	return n
+}
+
+// str returns n as a decimal string of length m, padded with leading zeros.
+func str(n *big.Int, m int) string {
+	s := n.Text(radix)
+	if len(s) >= m {
+		return s
+	}
+	b := make([]byte, m)
+	for i := range m - len(s) {
+		b[i] = '0'
+	}
+	copy(b[m-len(s):], s)
+	return string(b)
+}
+
+// pow returns radix^m.
+func pow(m int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(radix), big.NewInt(int64(m)), nil)
+}
+
+// mod returns x mod m in [0, m).
+func mod(x, m *big.Int) *big.Int {
+	// big.Int.Mod implements Euclidean modulus, which is never negative.
+	return x.Mod(x, m)
+}
+
+// putNum writes n big-endian into b, which must be large enough to hold it.
+func putNum(b []byte, n *big.Int) {
+	n.FillBytes(b)
+}
+
+// cbcMAC computes the AES-CBC MAC of data with a zero IV. len(data) must be a multiple of
+// the block size.
+func cbcMAC(block cipher.Block, data []byte) []byte {
+	y := make([]byte, block.BlockSize())
+	for i := 0; i < len(data); i += len(y) {
+		for j := range y {
+			y[j] ^= data[i+j]
+		}
+		block.Encrypt(y, y)
+	}
+	return y
+}
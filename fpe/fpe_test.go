@@ -0,0 +1,199 @@
+package fpe
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+// mustHex decodes a hex string or fails the test.
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex %q: %s", s, err)
+	}
+	return b
+}
+
+// NIST SP 800-38G sample vectors for radix 10.
+func TestFF1Samples(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   string
+		tweak string
+		pt    string
+		ct    string
+	}{
+		{"Sample 1", "2B7E151628AED2A6ABF7158809CF4F3C", "", "0123456789", "2433477484"},
+		{"Sample 2", "2B7E151628AED2A6ABF7158809CF4F3C", "39383736353433323130", "0123456789", "6124200773"},
+		{"Sample 4", "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "", "0123456789", "2830668132"},
+		{"Sample 5", "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F", "39383736353433323130", "0123456789", "2496655549"},
+		{"Sample 7", "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "", "0123456789", "6657667009"},
+		{"Sample 8", "2B7E151628AED2A6ABF7158809CF4F3CEF4359D8D580AA4F7F036D6F04FC6A94", "39383736353433323130", "0123456789", "1001623463"},
+	}
+
+	for _, c := range cases {
+		ff1, err := NewFF1(mustHex(t, c.key), mustHex(t, c.tweak))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+		ct, err := ff1.Encrypt(c.pt)
+		if err != nil || ct != c.ct {
+			t.Errorf("%s: Encrypt = %s, %v; want %s", c.name, ct, err, c.ct)
+		}
+		pt, err := ff1.Decrypt(c.ct)
+		if err != nil || pt != c.pt {
+			t.Errorf("%s: Decrypt = %s, %v; want %s", c.name, pt, err, c.pt)
+		}
+	}
+}
+
+// NIST SP 800-38G sample vectors for FF3 with radix 10. FF3-1 only differs in how the 64-bit
+// tweak is derived, which is tested separately.
+func TestFF3Samples(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   string
+		tweak string
+		pt    string
+		ct    string
+	}{
+		{"Sample 1", "EF4359D8D580AA4F7F036D6F04FC6A94", "D8E7920AFA330A73", "890121234567890000", "750918814058654607"},
+		{"Sample 2", "EF4359D8D580AA4F7F036D6F04FC6A94", "9A768A92F60E12D8", "890121234567890000", "018989839189395384"},
+		{"Sample 3", "EF4359D8D580AA4F7F036D6F04FC6A94", "D8E7920AFA330A73", "89012123456789000000789000000", "48598367162252569629397416226"},
+		{"Sample 4", "EF4359D8D580AA4F7F036D6F04FC6A94", "0000000000000000", "89012123456789000000789000000", "34695224821734535122613701434"},
+		{"Sample 6", "EF4359D8D580AA4F7F036D6F04FC6A942B7E151628AED2A6", "D8E7920AFA330A73", "890121234567890000", "646965393875028755"},
+		{"Sample 11", "EF4359D8D580AA4F7F036D6F04FC6A942B7E151628AED2A6ABF7158809CF4F3C", "D8E7920AFA330A73", "890121234567890000", "922011205562777495"},
+	}
+
+	for _, c := range cases {
+		ff3, err := newFF3(mustHex(t, c.key), [8]byte(mustHex(t, c.tweak)))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err)
+		}
+		ct, err := ff3.Encrypt(c.pt)
+		if err != nil || ct != c.ct {
+			t.Errorf("%s: Encrypt = %s, %v; want %s", c.name, ct, err, c.ct)
+		}
+		pt, err := ff3.Decrypt(c.ct)
+		if err != nil || pt != c.pt {
+			t.Errorf("%s: Decrypt = %s, %v; want %s", c.name, pt, err, c.pt)
+		}
+	}
+}
+
+func TestFF31Tweak(t *testing.T) {
+	key := mustHex(t, "EF4359D8D580AA4F7F036D6F04FC6A94")
+
+	// The 56-bit tweak D8E7920AFA330A expands to D8E79200 FA330AA0.
+	ff31, err := NewFF31(key, mustHex(t, "D8E7920AFA330A"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	ff3, err := newFF3(key, [8]byte(mustHex(t, "D8E79200FA330AA0")))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	const pt = "890121234567890000"
+	got, err := ff31.Encrypt(pt)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want, _ := ff3.Encrypt(pt); got != want {
+		t.Errorf("FF3-1 ciphertext %s doesn't match FF3 with the derived tweak %s", got, want)
+	}
+	if back, err := ff31.Decrypt(got); err != nil || back != pt {
+		t.Errorf("Decrypt = %s, %v; want %s", back, err, pt)
+	}
+
+	if _, err := NewFF31(key, mustHex(t, "D8E7920AFA330A73")); !errors.Is(err, ErrInvalidTweak) {
+		t.Errorf("expected ErrInvalidTweak, got %v", err)
+	}
+}
+
+func TestInputErrors(t *testing.T) {
+	ff1, _ := NewFF1(mustHex(t, "2B7E151628AED2A6ABF7158809CF4F3C"), nil)
+	ff31, _ := NewFF31(mustHex(t, "EF4359D8D580AA4F7F036D6F04FC6A94"), make([]byte, 7))
+
+	for _, c := range []Cipher{ff1, ff31} {
+		if _, err := c.Encrypt("12345"); !errors.Is(err, ErrInvalidLength) {
+			t.Errorf("%T: expected ErrInvalidLength, got %v", c, err)
+		}
+		if _, err := c.Encrypt("12345a"); !errors.Is(err, ErrInvalidNumeral) {
+			t.Errorf("%T: expected ErrInvalidNumeral, got %v", c, err)
+		}
+	}
+	if _, err := ff31.Encrypt(strings.Repeat("1", 57)); !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("expected ErrInvalidLength, got %v", err)
+	}
+	if _, err := NewFF1([]byte("short"), nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestEncryptPAN(t *testing.T) {
+	ff1, _ := NewFF1(mustHex(t, "2B7E151628AED2A6ABF7158809CF4F3C"), []byte("tweak"))
+	ff31, _ := NewFF31(mustHex(t, "EF4359D8D580AA4F7F036D6F04FC6A94"), make([]byte, 7))
+
+	const number = "4539983514929271"
+	opts := []PANOptions{
+		{},
+		{KeepBIN: true},
+		{KeepLast4: true, FixLuhn: true},
+		{KeepBIN: true, KeepLast4: true, FixLuhn: true},
+	}
+
+	for _, c := range []Cipher{ff1, ff31} {
+		for _, o := range opts {
+			ct, err := EncryptPAN(c, cardvalidate.NewPAN(number), o)
+			if err != nil {
+				t.Fatalf("%T %+v: unexpected error: %s", c, o, err)
+			}
+			raw := ct.Raw()
+			if len(raw) != len(number) || raw == number {
+				t.Errorf("%T %+v: unexpected ciphertext %s", c, o, raw)
+			}
+			if o.KeepBIN && raw[:6] != number[:6] {
+				t.Errorf("%T %+v: BIN not kept in %s", c, o, raw)
+			}
+			if o.KeepLast4 && raw[12:] != number[12:] {
+				t.Errorf("%T %+v: last 4 digits not kept in %s", c, o, raw)
+			}
+			if o.FixLuhn && o.KeepBIN {
+				if err := cardvalidate.Validate(raw, "12/2099"); err != nil {
+					t.Errorf("%T %+v: ciphertext %s doesn't validate: %s", c, o, raw, err)
+				}
+			}
+
+			pt, err := DecryptPAN(c, ct, o)
+			if err != nil || pt.Raw() != number {
+				t.Errorf("%T %+v: DecryptPAN = %s, %v", c, o, pt.Raw(), err)
+			}
+		}
+	}
+}
+
+func TestEncryptPANErrors(t *testing.T) {
+	ff1, _ := NewFF1(mustHex(t, "2B7E151628AED2A6ABF7158809CF4F3C"), nil)
+
+	cases := []struct {
+		number string
+		opts   PANOptions
+		err    error
+	}{
+		{"4111111111111112", PANOptions{FixLuhn: true}, ErrInvalidPAN},
+		{"41111111111a1111", PANOptions{}, ErrInvalidPAN},
+		{"4111111", PANOptions{}, ErrInvalidPAN},
+		{"378282246310005", PANOptions{KeepBIN: true, KeepLast4: true}, ErrInvalidLength},
+	}
+	for _, c := range cases {
+		if _, err := EncryptPAN(ff1, cardvalidate.NewPAN(c.number), c.opts); !errors.Is(err, c.err) {
+			t.Errorf("EncryptPAN(%s, %+v): expected %v, got %v", c.number, c.opts, c.err, err)
+		}
+	}
+}
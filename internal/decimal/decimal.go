@@ -0,0 +1,20 @@
+// Package decimal implements the decimalization of hex strings used by card network algorithms
+// such as CVV and Visa PVV.
+package decimal
+
+// FromHex returns the first n decimal digits of lower-case hex string s, followed by its letters
+// a-f converted to digits 0-5 if there are too few of them.
+func FromHex(s string, n int) string {
+	out := make([]byte, 0, n)
+	for _, c := range []byte(s) {
+		if len(out) < n && c >= '0' && c <= '9' {
+			out = append(out, c)
+		}
+	}
+	for _, c := range []byte(s) {
+		if len(out) < n && c >= 'a' && c <= 'f' {
+			out = append(out, c-'a'+'0')
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,22 @@
+package decimal
+
+import "testing"
+
+func TestFromHex(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"5b614982e03c97dd", 3, "561"},
+		{"5b614982e03c97dd", 4, "5614"},
+		{"abcdef12", 4, "1201"},
+		{"fedcba98", 8, "98543210"},
+		{"", 3, ""},
+	}
+	for _, tc := range tests {
+		if have := FromHex(tc.s, tc.n); have != tc.want {
+			t.Errorf("FromHex(%q, %d): want %q have %q", tc.s, tc.n, tc.want, have)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package luhn implements the Luhn (mod 10) checksum used by credit card numbers.
+package luhn
+
+// Valid does Luhn's check (mod 10 check) on number.
+// It assumes that number contains only ASCII digits.
+func Valid[T ~string | ~[]byte](number T) bool {
+	return sum(number, false)%10 == 0
+}
+
+// CheckDigit returns the check digit that makes payload followed by it pass Luhn's check.
+// It assumes that payload contains only ASCII digits.
+func CheckDigit(payload string) byte {
+	return byte('0' + (10-sum(payload, true)%10)%10)
+}
+
+// sum computes Luhn's sum of number's digits going from right to left. If double is true,
+// doubling starts from the rightmost digit, which is what the check digit computation needs.
+func sum[T ~string | ~[]byte](number T, double bool) int {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		n := int(number[i] - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		double = !double
+	}
+	return sum
+}
+
+// CountCompletions returns the number of ways to replace non-digit characters of pattern
+// with digits so that the result passes Luhn's check.
+func CountCompletions(pattern string) uint64 {
+	// counts[s] is the number of completions of the processed suffix whose Luhn's sum is
+	// congruent to s modulo 10.
+	var counts [10]uint64
+	counts[0] = 1
+
+	double := false
+	for i := len(pattern) - 1; i >= 0; i-- {
+		var next [10]uint64
+		for d := 0; d <= 9; d++ {
+			if c := pattern[i]; '0' <= c && c <= '9' && int(c-'0') != d {
+				continue
+			}
+
+			n := d
+			if double {
+				n *= 2
+				if n > 9 {
+					n -= 9
+				}
+			}
+			for s, count := range counts {
+				next[(s+n)%10] += count
+			}
+		}
+		counts = next
+		double = !double
+	}
+	return counts[0]
+}
@@ -0,0 +1,60 @@
+package luhn
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"378282246310005", true},
+		{"6212345678900000003", true},
+		{"4111111111111121", false},
+		{"6212345678911036", false},
+	}
+
+	for _, tc := range tests {
+		if have := Valid(tc.number); have != tc.want {
+			t.Errorf("Valid(%s): want %t have %t", tc.number, tc.want, have)
+		}
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		payload string
+		want    byte
+	}{
+		{"411111111111111", '1'},
+		{"37828224631000", '5'},
+		{"601111111111111", '7'},
+		{"555555555555444", '4'},
+	}
+
+	for _, tc := range tests {
+		if have := CheckDigit(tc.payload); have != tc.want {
+			t.Errorf("CheckDigit(%s): want %c have %c", tc.payload, tc.want, have)
+		}
+	}
+}
+
+func TestCountCompletions(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    uint64
+	}{
+		{"4111111111111111", 1},
+		{"4111111111111112", 0},
+		{"411111111111111*", 1},
+		{"41111111111111**", 10},
+		{"411111******1111", 100000},
+		{"****", 1000},
+	}
+
+	for _, tc := range tests {
+		if have := CountCompletions(tc.pattern); have != tc.want {
+			t.Errorf("CountCompletions(%s): want %d have %d", tc.pattern, tc.want, have)
+		}
+	}
+}
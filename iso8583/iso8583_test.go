@@ -0,0 +1,211 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// bitmapFor returns primary and, if needed, secondary bitmap with given fields set.
+func bitmapFor(fields ...int) []byte {
+	bitmap := make([]byte, 8)
+	for _, n := range fields {
+		if n > 64 && len(bitmap) == 8 {
+			bitmap = append(bitmap, make([]byte, 8)...)
+			bitmap[0] |= 0x80
+		}
+		bitmap[(n-1)/8] |= 0x80 >> ((n - 1) % 8)
+	}
+	return bitmap
+}
+
+// mustHex decodes s or fails the test.
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex %s: %s", s, err)
+	}
+	return b
+}
+
+const (
+	testPAN    = "4111111111111111"
+	testTrack2 = "4111111111111111=2812201123456789"
+	testPIN    = "0123456789ABCDEF"
+)
+
+// asciiMessage returns an authorization request encoded according to ASCIISpec.
+func asciiMessage(t *testing.T, pan, track2 string) []byte {
+	var b []byte
+	b = append(b, "0100"...)
+	b = append(b, strings.ToUpper(hex.EncodeToString(bitmapFor(2, 3, 4, 11, 14, 22, 35, 41, 49, 52, 128)))...)
+	b = append(b, "16"+pan...)
+	b = append(b, "000000"...)
+	b = append(b, "000000001000"...)
+	b = append(b, "123456"...)
+	b = append(b, "2812"...)
+	b = append(b, "051"...)
+	b = append(b, "33"+track2...)
+	b = append(b, "TERM0001"...)
+	b = append(b, "840"...)
+	b = append(b, mustHex(t, testPIN)...)
+	b = append(b, mustHex(t, "0102030405060708")...)
+	return b
+}
+
+// bcdMessage returns the same request as asciiMessage encoded according to BCDSpec.
+func bcdMessage(t *testing.T) []byte {
+	var b []byte
+	b = append(b, 0x01, 0x00)
+	b = append(b, bitmapFor(2, 3, 4, 11, 14, 22, 35, 41, 49, 52, 128)...)
+	b = append(b, mustHex(t, "16"+testPAN)...)
+	b = append(b, mustHex(t, "000000")...)
+	b = append(b, mustHex(t, "000000001000")...)
+	b = append(b, mustHex(t, "123456")...)
+	b = append(b, mustHex(t, "2812")...)
+	b = append(b, mustHex(t, "0051")...)
+	b = append(b, mustHex(t, "33"+strings.Replace(testTrack2, "=", "D", 1)+"F")...)
+	b = append(b, "TERM0001"...)
+	b = append(b, mustHex(t, "0840")...)
+	b = append(b, mustHex(t, testPIN)...)
+	b = append(b, mustHex(t, "0102030405060708")...)
+	return b
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   *Spec
+		data   []byte
+		track2 string
+	}{
+		{"ASCII", ASCIISpec(), asciiMessage(t, testPAN, testTrack2), testTrack2},
+		{"BCD", BCDSpec(), bcdMessage(t), strings.Replace(testTrack2, "=", "D", 1)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, err := tc.spec.Parse(tc.data)
+			if err != nil {
+				t.Fatalf("unexpected parsing error: %s", err)
+			}
+
+			want := map[int]string{
+				FieldPAN:            testPAN,
+				FieldProcessingCode: "000000",
+				FieldAmount:         "000000001000",
+				FieldSTAN:           "123456",
+				FieldExpirationDate: "2812",
+				FieldPOSEntryMode:   "051",
+				FieldTrack2:         tc.track2,
+				FieldTerminalID:     "TERM0001",
+				FieldCurrency:       "840",
+				FieldPINData:        testPIN,
+				FieldMAC2:           "0102030405060708",
+			}
+			if msg.MTI != "0100" || len(msg.Fields) != len(want) {
+				t.Fatalf("unexpected message: %+v", msg)
+			}
+			for n, v := range want {
+				if msg.Fields[n] != v {
+					t.Errorf("DE%03d mismatch: want %s have %s", n, v, msg.Fields[n])
+				}
+			}
+
+			if _, err := msg.Validate(); err != nil {
+				t.Fatalf("unexpected validation error: %s", err)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	msg, err := ASCIISpec().Parse(asciiMessage(t, testPAN, testTrack2))
+	if err != nil {
+		t.Fatalf("unexpected parsing error: %s", err)
+	}
+
+	dump := msg.Redacted()
+	for _, secret := range []string{testPAN, testTrack2, "2201123456789", testPIN} {
+		if strings.Contains(dump, secret) {
+			t.Errorf("redacted dump contains %s:\n%s", secret, dump)
+		}
+	}
+	for _, line := range []string{
+		"MTI 0100",
+		"DE002 Primary Account Number: 411111******1111",
+		"DE035 Track 2 Data: 411111******1111=[REDACTED]",
+		"DE052 PIN Data: [REDACTED 16]",
+		"DE041 Card Acceptor Terminal ID: TERM0001",
+	} {
+		if !strings.Contains(dump, line+"\n") {
+			t.Errorf("redacted dump is missing %q:\n%s", line, dump)
+		}
+	}
+}
+
+func TestRedactedUnknownFields(t *testing.T) {
+	fields := map[int]string{FieldPAN: testPAN, FieldTerminalID: "TERM0001", 120: "secret"}
+	spec := ASCIISpec()
+	delete(spec.Fields, FieldTerminalID)
+	spec.Fields[FieldPAN].Name = "PAN"
+
+	tests := []struct {
+		msg     *Message
+		panName string
+	}{
+		{&Message{MTI: "0100", Fields: fields}, "Unknown"},
+		{&Message{MTI: "0100", Fields: fields, spec: spec}, "PAN"},
+	}
+	for _, tc := range tests {
+		dump := tc.msg.Redacted()
+		for _, line := range []string{
+			"DE002 " + tc.panName + ": 411111******1111",
+			"DE041 Unknown: [REDACTED 8]",
+			"DE120 Unknown: [REDACTED 6]",
+		} {
+			if !strings.Contains(dump, line+"\n") {
+				t.Errorf("redacted dump is missing %q:\n%s", line, dump)
+			}
+		}
+	}
+
+	if DefaultFields[FieldTerminalID] == nil || DefaultFields[FieldPAN].Name != "Primary Account Number" {
+		t.Error("modifying a spec changed DefaultFields")
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	valid := asciiMessage(t, testPAN, testTrack2)
+
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{valid[:3], ErrMalformedMessage},
+		{valid[:30], ErrMalformedMessage},
+		{append(valid, 'X'), ErrMalformedMessage},
+		{[]byte("0100ZZZZZZZZZZZZZZZZ"), ErrMalformedMessage},
+		{[]byte("01000000000000000002" + "X"), ErrUnknownField},
+		{[]byte("01004000000000000000" + "2041111111111111111111"), ErrMalformedMessage},
+		{[]byte("0100" + "4000000000000000" + "-1" + "4111"), ErrMalformedMessage},
+		{[]byte("0100" + "4000000000000000" + "+5" + "41111"), ErrMalformedMessage},
+	}
+
+	for _, tc := range tests {
+		if _, err := ASCIISpec().Parse(tc.data); !errors.Is(err, tc.err) {
+			t.Errorf("unexpected error for %q: want %s have %v", tc.data, tc.err, err)
+		}
+	}
+}
+
+func TestCardDataMismatch(t *testing.T) {
+	msg, err := ASCIISpec().Parse(asciiMessage(t, "4012888888881881", testTrack2))
+	if err != nil {
+		t.Fatalf("unexpected parsing error: %s", err)
+	}
+	if _, err := msg.Validate(); !errors.Is(err, ErrDataMismatch) {
+		t.Fatalf("expected ErrDataMismatch, got %v", err)
+	}
+}
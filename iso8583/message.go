@@ -0,0 +1,255 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/track"
+)
+
+var (
+	ErrMalformedMessage = errors.New("iso8583: malformed message")
+	ErrUnknownField     = errors.New("iso8583: unknown field")
+	ErrMissingCardData  = errors.New("iso8583: message has no card data")
+	ErrDataMismatch     = errors.New("iso8583: track 2 data doesn't match card data")
+)
+
+// Message is a parsed ISO 8583 message. Numeric and text field values are stored as is,
+// binary ones are hex-encoded.
+type Message struct {
+	MTI    string
+	Fields map[int]string
+	spec   *Spec
+}
+
+// Parse parses a message according to s.
+func (s *Spec) Parse(data []byte) (*Message, error) {
+	r := &reader{data: data}
+
+	mti, err := r.numeric(4, s.MTIEncoding, false)
+	if err != nil {
+		return nil, fmt.Errorf("MTI: %w", err)
+	}
+
+	bitmap, err := r.bitmap(s.HexBitmap)
+	if err != nil {
+		return nil, err
+	}
+	if bitmap[0]&0x80 != 0 {
+		secondary, err := r.bitmap(s.HexBitmap)
+		if err != nil {
+			return nil, err
+		}
+		bitmap = append(bitmap, secondary...)
+	}
+
+	msg := &Message{MTI: mti, Fields: make(map[int]string), spec: s}
+	for i := 1; i < len(bitmap)*8; i++ {
+		if bitmap[i/8]&(0x80>>(i%8)) == 0 {
+			continue
+		}
+		n := i + 1
+		if n == 65 {
+			// Bit 65 would indicate a tertiary bitmap, which is not supported.
+			return nil, fmt.Errorf("%w: tertiary bitmap", ErrMalformedMessage)
+		}
+
+		field, ok := s.Fields[n]
+		if !ok {
+			return nil, fmt.Errorf("%w: DE%03d", ErrUnknownField, n)
+		}
+		if msg.Fields[n], err = r.field(s, field); err != nil {
+			return nil, fmt.Errorf("DE%03d: %w", n, err)
+		}
+	}
+
+	if len(r.data) > 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrMalformedMessage, len(r.data))
+	}
+	return msg, nil
+}
+
+// CardData returns the PAN and the expiration date in YYMM format. They are taken from DE2 and
+// DE14 if present or from track 2 data in DE35 otherwise. If both are present they must match.
+func (m *Message) CardData() (pan, expiry string, err error) {
+	pan, expiry = m.Fields[FieldPAN], m.Fields[FieldExpirationDate]
+
+	if raw, ok := m.Fields[FieldTrack2]; ok {
+		t2, err := track.ParseTrack2Equivalent(raw)
+		if err != nil {
+			return "", "", fmt.Errorf("DE%03d: %w", FieldTrack2, err)
+		}
+		if pan != "" && pan != t2.PAN {
+			return "", "", fmt.Errorf("%w: PAN", ErrDataMismatch)
+		}
+		if expiry != "" && expiry != t2.Expiry {
+			return "", "", fmt.Errorf("%w: expiration date", ErrDataMismatch)
+		}
+		pan, expiry = t2.PAN, t2.Expiry
+	}
+
+	if pan == "" || len(expiry) != 4 {
+		return "", "", ErrMissingCardData
+	}
+	return pan, expiry, nil
+}
+
+// Validate validates card data extracted from the message with cardvalidate.
+func (m *Message) Validate() (cardvalidate.Result, error) {
+	pan, expiry, err := m.CardData()
+	if err != nil {
+		return cardvalidate.Result{}, err
+	}
+	return cardvalidate.ValidateCard(pan, expiry[2:]+"/20"+expiry[:2])
+}
+
+// Redacted returns a human-readable dump of the message that is safe to log. PANs are masked
+// and other sensitive fields are replaced with their length, as are fields that the message's
+// spec doesn't know.
+func (m *Message) Redacted() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "MTI %s\n", m.MTI)
+
+	fields := make([]int, 0, len(m.Fields))
+	for n := range m.Fields {
+		fields = append(fields, n)
+	}
+	slices.Sort(fields)
+
+	for _, n := range fields {
+		value := m.Fields[n]
+		var field *Field
+		if m.spec != nil {
+			field = m.spec.Fields[n]
+		}
+		if field == nil {
+			field = &Field{Name: "Unknown", Sensitive: true}
+		}
+		switch {
+		case n == FieldPAN:
+			value = cardvalidate.MaskPAN(value)
+		case n == FieldTrack2:
+			if t2, err := track.ParseTrack2Equivalent(value); err == nil {
+				value = cardvalidate.MaskPAN(t2.PAN) + "=[REDACTED]"
+			} else {
+				value = "[REDACTED]"
+			}
+		case field.Sensitive:
+			value = "[REDACTED " + strconv.Itoa(len(value)) + "]"
+		}
+		fmt.Fprintf(&b, "DE%03d %s: %s\n", n, field.Name, value)
+	}
+	return b.String()
+}
+
+// reader consumes message bytes.
+type reader struct {
+	data []byte
+}
+
+// next consumes n bytes.
+func (r *reader) next(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w: negative length %d", ErrMalformedMessage, n)
+	}
+	if n > len(r.data) {
+		return nil, fmt.Errorf("%w: unexpected end of message", ErrMalformedMessage)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b, nil
+}
+
+// bitmap consumes an 8-byte bitmap.
+func (r *reader) bitmap(isHex bool) ([]byte, error) {
+	if !isHex {
+		b, err := r.next(8)
+		return slices.Clone(b), err
+	}
+
+	b, err := r.next(16)
+	if err != nil {
+		return nil, err
+	}
+	bitmap, err := hex.DecodeString(string(b))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid bitmap", ErrMalformedMessage)
+	}
+	return bitmap, nil
+}
+
+// numeric consumes n digits. BCD values with an odd number of digits are padded with a leading
+// zero, or with a trailing 'F' if padRight is set.
+func (r *reader) numeric(n int, enc Encoding, padRight bool) (string, error) {
+	if enc == ASCII {
+		b, err := r.next(n)
+		return string(b), err
+	}
+
+	b, err := r.next((n + 1) / 2)
+	if err != nil {
+		return "", err
+	}
+	s := strings.ToUpper(hex.EncodeToString(b))
+	if len(s) > n {
+		if padRight {
+			s = s[:n]
+		} else {
+			s = s[1:]
+		}
+	}
+	return s, nil
+}
+
+// field consumes a data element.
+func (r *reader) field(s *Spec, f *Field) (string, error) {
+	length := f.Length
+	if f.Prefix > 0 {
+		prefix, err := r.numeric(f.Prefix, s.LengthEncoding, false)
+		if err != nil {
+			return "", err
+		}
+		if !isDigits(prefix) {
+			return "", fmt.Errorf("%w: invalid length prefix %q", ErrMalformedMessage, prefix)
+		}
+		if length, err = strconv.Atoi(prefix); err != nil {
+			return "", fmt.Errorf("%w: invalid length prefix %q", ErrMalformedMessage, prefix)
+		}
+		if length > f.Length {
+			return "", fmt.Errorf("%w: length %d exceeds maximum %d", ErrMalformedMessage, length, f.Length)
+		}
+	}
+
+	switch f.Type {
+	case Numeric, Track2:
+		v, err := r.numeric(length, s.NumericEncoding, f.Type == Track2)
+		if err != nil {
+			return "", err
+		}
+		if f.Type == Numeric && !isDigits(v) {
+			return "", fmt.Errorf("%w: non-numeric value", ErrMalformedMessage)
+		}
+		return v, nil
+	case Binary:
+		b, err := r.next(length)
+		return strings.ToUpper(hex.EncodeToString(b)), err
+	default:
+		b, err := r.next(length)
+		return string(b), err
+	}
+}
+
+// isDigits checks if s consists of ASCII digits only.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
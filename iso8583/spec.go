@@ -0,0 +1,132 @@
+// Package iso8583 parses ISO 8583 financial transaction messages and extracts card data from them.
+package iso8583
+
+// Encoding of numeric values and length prefixes.
+type Encoding int
+
+const (
+	ASCII Encoding = iota // One ASCII character per digit.
+	BCD                   // Two digits per byte.
+)
+
+// FieldType is the ISO 8583 data element type.
+type FieldType int
+
+const (
+	Numeric FieldType = iota // n: digits.
+	Track2                   // z: track 2 code set, digits and the 'D' or '=' separator.
+	Text                     // an, ans: alphanumeric and special characters.
+	Binary                   // b: raw bytes, lengths count bytes.
+)
+
+// Field describes a data element.
+type Field struct {
+	Name      string
+	Type      FieldType
+	Length    int  // Fixed length, or maximum length of variable fields.
+	Prefix    int  // Number of length prefix digits: 0 for fixed fields, 2 for LLVAR, 3 for LLLVAR.
+	Sensitive bool // Value must not be logged as is.
+}
+
+// Spec describes how messages are encoded.
+type Spec struct {
+	MTIEncoding     Encoding // Encoding of the message type indicator.
+	NumericEncoding Encoding // Encoding of numeric and track 2 fields.
+	LengthEncoding  Encoding // Encoding of LLVAR and LLLVAR length prefixes.
+	HexBitmap       bool     // Bitmaps are encoded as 16 hex characters instead of 8 raw bytes.
+	Fields          map[int]*Field
+}
+
+// ASCIISpec returns a spec for messages with all numbers, lengths and bitmaps encoded as ASCII.
+// Each call returns a new spec with its own copy of DefaultFields.
+func ASCIISpec() *Spec {
+	return &Spec{
+		MTIEncoding:     ASCII,
+		NumericEncoding: ASCII,
+		LengthEncoding:  ASCII,
+		HexBitmap:       true,
+		Fields:          defaultFields(),
+	}
+}
+
+// BCDSpec returns a spec for messages with numbers and lengths encoded as BCD and a binary bitmap.
+func BCDSpec() *Spec {
+	return &Spec{
+		MTIEncoding:     BCD,
+		NumericEncoding: BCD,
+		LengthEncoding:  BCD,
+		Fields:          defaultFields(),
+	}
+}
+
+// Common data elements from ISO 8583:1987.
+const (
+	FieldPAN              = 2
+	FieldProcessingCode   = 3
+	FieldAmount           = 4
+	FieldTransmissionTime = 7
+	FieldSTAN             = 11
+	FieldLocalTime        = 12
+	FieldLocalDate        = 13
+	FieldExpirationDate   = 14
+	FieldMerchantType     = 18
+	FieldPOSEntryMode     = 22
+	FieldCardSequence     = 23
+	FieldPOSCondition     = 25
+	FieldAcquirerID       = 32
+	FieldTrack2           = 35
+	FieldRRN              = 37
+	FieldAuthCode         = 38
+	FieldResponseCode     = 39
+	FieldTerminalID       = 41
+	FieldMerchantID       = 42
+	FieldMerchantName     = 43
+	FieldTrack1           = 45
+	FieldCurrency         = 49
+	FieldPINData          = 52
+	FieldSecurityControl  = 53
+	FieldICCData          = 55
+	FieldMAC              = 64
+	FieldMAC2             = 128
+)
+
+// DefaultFields are the data elements known to the default specs.
+var DefaultFields = map[int]*Field{
+	FieldPAN:              {Name: "Primary Account Number", Type: Numeric, Length: 19, Prefix: 2, Sensitive: true},
+	FieldProcessingCode:   {Name: "Processing Code", Type: Numeric, Length: 6},
+	FieldAmount:           {Name: "Amount, Transaction", Type: Numeric, Length: 12},
+	FieldTransmissionTime: {Name: "Transmission Date and Time", Type: Numeric, Length: 10},
+	FieldSTAN:             {Name: "System Trace Audit Number", Type: Numeric, Length: 6},
+	FieldLocalTime:        {Name: "Time, Local Transaction", Type: Numeric, Length: 6},
+	FieldLocalDate:        {Name: "Date, Local Transaction", Type: Numeric, Length: 4},
+	FieldExpirationDate:   {Name: "Date, Expiration", Type: Numeric, Length: 4},
+	FieldMerchantType:     {Name: "Merchant Type", Type: Numeric, Length: 4},
+	FieldPOSEntryMode:     {Name: "POS Entry Mode", Type: Numeric, Length: 3},
+	FieldCardSequence:     {Name: "Card Sequence Number", Type: Numeric, Length: 3},
+	FieldPOSCondition:     {Name: "POS Condition Code", Type: Numeric, Length: 2},
+	FieldAcquirerID:       {Name: "Acquiring Institution ID", Type: Numeric, Length: 11, Prefix: 2},
+	FieldTrack2:           {Name: "Track 2 Data", Type: Track2, Length: 37, Prefix: 2, Sensitive: true},
+	FieldRRN:              {Name: "Retrieval Reference Number", Type: Text, Length: 12},
+	FieldAuthCode:         {Name: "Authorization ID Response", Type: Text, Length: 6},
+	FieldResponseCode:     {Name: "Response Code", Type: Text, Length: 2},
+	FieldTerminalID:       {Name: "Card Acceptor Terminal ID", Type: Text, Length: 8},
+	FieldMerchantID:       {Name: "Card Acceptor ID Code", Type: Text, Length: 15},
+	FieldMerchantName:     {Name: "Card Acceptor Name/Location", Type: Text, Length: 40},
+	FieldTrack1:           {Name: "Track 1 Data", Type: Text, Length: 76, Prefix: 2, Sensitive: true},
+	FieldCurrency:         {Name: "Currency Code, Transaction", Type: Numeric, Length: 3},
+	FieldPINData:          {Name: "PIN Data", Type: Binary, Length: 8, Sensitive: true},
+	FieldSecurityControl:  {Name: "Security Related Control Information", Type: Numeric, Length: 16},
+	FieldICCData:          {Name: "ICC Data", Type: Binary, Length: 255, Prefix: 3, Sensitive: true},
+	FieldMAC:              {Name: "Message Authentication Code", Type: Binary, Length: 8},
+	FieldMAC2:             {Name: "Message Authentication Code", Type: Binary, Length: 8},
+}
+
+// defaultFields returns a copy of DefaultFields that specs can modify without affecting others.
+func defaultFields() map[int]*Field {
+	fields := make(map[int]*Field, len(DefaultFields))
+	for n, f := range DefaultFields {
+		clone := *f
+		fields[n] = &clone
+	}
+	return fields
+}
@@ -0,0 +1,185 @@
+package issuer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Card funding types.
+const (
+	FundingCredit  = "credit"
+	FundingDebit   = "debit"
+	FundingPrepaid = "prepaid"
+)
+
+// ErrMalformedBINDatabase is returned when a BIN database file can't be parsed.
+var ErrMalformedBINDatabase = errors.New("issuer: malformed BIN database")
+
+// BINInfo describes the card product behind a bank identification number.
+type BINInfo struct {
+	BIN     string `json:"bin"`     // 6 or 8 leading digits of the card number.
+	Funding string `json:"funding"` // Funding type: credit, debit or prepaid.
+	Level   string `json:"level"`   // Product level, e.g. classic, gold, platinum.
+	Bank    string `json:"bank"`    // Issuing bank name.
+	Country string `json:"country"` // ISO 3166-1 alpha-2 country code.
+}
+
+// Details is a registry entry merged with its BIN database record.
+type Details struct {
+	Entry
+	BINInfo
+}
+
+// BINDatabase is an in-memory BIN database indexed by 6 and 8-digit BINs.
+// A nil *BINDatabase is valid and contains no records.
+type BINDatabase struct {
+	bins map[string]BINInfo
+}
+
+// NewBINDatabase builds a database from records and returns an error if any of them is invalid.
+func NewBINDatabase(records []BINInfo) (*BINDatabase, error) {
+	db := &BINDatabase{bins: make(map[string]BINInfo, len(records))}
+	for _, rec := range records {
+		if err := validateBINInfo(rec); err != nil {
+			return nil, err
+		}
+		if _, ok := db.bins[rec.BIN]; ok {
+			return nil, fmt.Errorf("%w: duplicate BIN %s", ErrMalformedBINDatabase, rec.BIN)
+		}
+		db.bins[rec.BIN] = rec
+	}
+	return db, nil
+}
+
+// LoadBINDatabase reads a BIN database from a local CSV or JSON file. The format is chosen
+// by the file extension.
+func LoadBINDatabase(path string) (*BINDatabase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv":
+		return ParseBINCSV(f)
+	case ".json":
+		return ParseBINJSON(f)
+	default:
+		return nil, fmt.Errorf("%w: unsupported file extension %q", ErrMalformedBINDatabase, ext)
+	}
+}
+
+// binColumns are the columns of a BIN database CSV file.
+var binColumns = []string{"bin", "funding", "level", "bank", "country"}
+
+// ParseBINCSV parses a BIN database from CSV with a header row of
+// bin,funding,level,bank,country. Columns are matched by name, so they may come in any order,
+// and other columns are ignored.
+func ParseBINCSV(r io.Reader) (*BINDatabase, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedBINDatabase, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: missing header", ErrMalformedBINDatabase)
+	}
+
+	// Index of each of binColumns in the header.
+	index := make([]int, len(binColumns))
+	for i, name := range binColumns {
+		index[i] = slices.IndexFunc(rows[0], func(column string) bool {
+			return strings.EqualFold(strings.TrimSpace(column), name)
+		})
+		if index[i] < 0 {
+			return nil, fmt.Errorf("%w: missing %q column", ErrMalformedBINDatabase, name)
+		}
+	}
+
+	records := make([]BINInfo, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		records = append(records, BINInfo{
+			BIN:     row[index[0]],
+			Funding: row[index[1]],
+			Level:   row[index[2]],
+			Bank:    row[index[3]],
+			Country: row[index[4]],
+		})
+	}
+	return NewBINDatabase(records)
+}
+
+// ParseBINJSON parses a BIN database from a JSON array of BINInfo objects.
+func ParseBINJSON(r io.Reader) (*BINDatabase, error) {
+	var records []BINInfo
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrMalformedBINDatabase, err)
+	}
+	return NewBINDatabase(records)
+}
+
+// Len returns the number of records in the database.
+func (db *BINDatabase) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.bins)
+}
+
+// BIN returns the database record for cardNumber, preferring an 8-digit BIN over a 6-digit one.
+func (db *BINDatabase) BIN(cardNumber string) (BINInfo, bool) {
+	if db == nil {
+		return BINInfo{}, false
+	}
+	for _, n := range []int{8, 6} {
+		if len(cardNumber) < n {
+			continue
+		}
+		if info, ok := db.bins[cardNumber[:n]]; ok {
+			return info, true
+		}
+	}
+	return BINInfo{}, false
+}
+
+// Details merges entry, the registry entry cardNumber was looked up in, with the BIN database
+// record for cardNumber if there is one.
+func (db *BINDatabase) Details(entry Entry, cardNumber string) Details {
+	info, _ := db.BIN(cardNumber)
+	return Details{Entry: entry, BINInfo: info}
+}
+
+// validateBINInfo checks that rec has a well-formed BIN, funding type and country code.
+func validateBINInfo(rec BINInfo) error {
+	if len(rec.BIN) != 6 && len(rec.BIN) != 8 {
+		return fmt.Errorf("%w: BIN %q must have 6 or 8 digits", ErrMalformedBINDatabase, rec.BIN)
+	}
+	for _, r := range rec.BIN {
+		if r < '0' || r > '9' {
+			return fmt.Errorf("%w: BIN %q must have 6 or 8 digits", ErrMalformedBINDatabase, rec.BIN)
+		}
+	}
+
+	switch rec.Funding {
+	case "", FundingCredit, FundingDebit, FundingPrepaid:
+	default:
+		return fmt.Errorf("%w: unknown funding type %q", ErrMalformedBINDatabase, rec.Funding)
+	}
+
+	if rec.Country != "" && !isCountryCode(rec.Country) {
+		return fmt.Errorf("%w: invalid country code %q", ErrMalformedBINDatabase, rec.Country)
+	}
+	return nil
+}
+
+// isCountryCode checks if s is two uppercase ASCII letters like an ISO 3166-1 alpha-2 code.
+func isCountryCode(s string) bool {
+	return len(s) == 2 && 'A' <= s[0] && s[0] <= 'Z' && 'A' <= s[1] && s[1] <= 'Z'
+}
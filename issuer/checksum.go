@@ -0,0 +1,98 @@
+package issuer
+
+import "github.com/waterfountain1996/cardvalidate/internal/luhn"
+
+// Checksum is a check digit algorithm used by an IIN range.
+type Checksum struct {
+	name   string
+	verify func(cardNumber string) bool
+	count  func(pattern string) uint64 // Counts completions faster than trying each one, optional.
+}
+
+var (
+	// Luhn is the mod 10 check used by the vast majority of payment cards.
+	Luhn = Checksum{name: "luhn", verify: luhn.Valid[string], count: luhn.CountCompletions}
+
+	// NoChecksum is used by ranges whose card numbers don't carry a check digit.
+	NoChecksum = NewChecksum("none", nil)
+)
+
+// NewChecksum returns a custom checksum algorithm. A nil verify function accepts any
+// card number.
+func NewChecksum(name string, verify func(cardNumber string) bool) Checksum {
+	return Checksum{
+		name:   name,
+		verify: verify,
+	}
+}
+
+// ChecksumByName returns a built-in checksum algorithm by its name.
+func ChecksumByName(name string) (Checksum, bool) {
+	switch name {
+	case Luhn.name:
+		return Luhn, true
+	case NoChecksum.name:
+		return NoChecksum, true
+	default:
+		return Checksum{}, false
+	}
+}
+
+// Name returns the algorithm name.
+func (c Checksum) Name() string {
+	return c.name
+}
+
+// String implements fmt.Stringer
+func (c Checksum) String() string {
+	return c.name
+}
+
+// Verify checks cardNumber against the algorithm.
+func (c Checksum) Verify(cardNumber string) bool {
+	if c.verify == nil {
+		return true
+	}
+	return c.verify(cardNumber)
+}
+
+// Completions returns the number of ways to replace non-digit characters of pattern with digits
+// so that the result passes the algorithm's check. Custom algorithms check every completion,
+// so this takes time exponential in the number of non-digit characters.
+func (c Checksum) Completions(pattern string) uint64 {
+	if c.count != nil {
+		return c.count(pattern)
+	}
+
+	b := []byte(pattern)
+	var unknown []int
+	for i, r := range b {
+		if r < '0' || r > '9' {
+			unknown = append(unknown, i)
+		}
+	}
+	if c.verify == nil {
+		n := uint64(1)
+		for range unknown {
+			n *= 10
+		}
+		return n
+	}
+
+	var n uint64
+	var fill func(k int)
+	fill = func(k int) {
+		if k == len(unknown) {
+			if c.verify(string(b)) {
+				n++
+			}
+			return
+		}
+		for d := byte('0'); d <= '9'; d++ {
+			b[unknown[k]] = d
+			fill(k + 1)
+		}
+	}
+	fill(0)
+	return n
+}
@@ -3,6 +3,7 @@ package issuer
 
 import (
 	"fmt"
+	"time"
 )
 
 // Credit card issuer.
@@ -17,6 +18,7 @@ const (
 	MasterCard
 	UnionPay
 	Visa
+	PrivateLabel // Store, fleet and other closed-loop cards registered at runtime.
 )
 
 // String implements fmt.Stringer
@@ -36,39 +38,100 @@ func (i Issuer) String() string {
 		return "UnionPay"
 	case Visa:
 		return "Visa"
+	case PrivateLabel:
+		return "Private Label"
 	default:
 		return fmt.Sprintf("Unknown(%d)", i)
 	}
 }
 
-// iinTrie is a prefix tree that contains all known credit card issuers' identification numbers.
-var iinTrie = newTrie()
-
-func init() {
-	items := []struct {
-		Issuer Issuer
-		Prefix intRange // IIN range.
-		Length intRange // Credit card number length.
-	}{
-		{AmericanExpress, newSingleIntRange(34), newSingleIntRange(15)},
-		{AmericanExpress, newSingleIntRange(37), newSingleIntRange(15)},
-		{DinersClub, newSingleIntRange(30), newSingleIntRange(14)},
-		{DinersClub, newSingleIntRange(36), newSingleIntRange(14)},
-		{DinersClub, newSingleIntRange(38), newSingleIntRange(14)},
-		{DinersClub, newSingleIntRange(39), newSingleIntRange(14)},
-		{Discover, newSingleIntRange(6011), newSingleIntRange(16)},
-		{Discover, newIntRange(644, 649), newSingleIntRange(16)},
-		{Discover, newSingleIntRange(65), newSingleIntRange(16)},
-		{JCB, newIntRange(3528, 3589), newSingleIntRange(16)},
-		{MasterCard, newIntRange(51, 55), newSingleIntRange(16)},
-		{MasterCard, newIntRange(2221, 2720), newSingleIntRange(16)},
-		{UnionPay, newSingleIntRange(62), newIntRange(13, 19)},
-		{Visa, newSingleIntRange(4), newSingleIntRange(16)},
+// Entry is a single IIN range in the issuer registry.
+type Entry struct {
+	Issuer   Issuer
+	Name     string   // Issuer name, overrides Issuer.String() for private-label ranges.
+	Prefix   IntRange // IIN range.
+	Length   IntRange // Credit card number length.
+	Checksum Checksum // Check digit algorithm, Luhn if not set.
+
+	// Most common card number lengths within Length. Zero value means all of them are common.
+	TypicalLength IntRange
+
+	// Period during which the range is in use. Zero values leave the period unbounded,
+	// EffectiveUntil is exclusive.
+	EffectiveFrom  time.Time
+	EffectiveUntil time.Time
+}
+
+// IssuerName returns the name of the range's issuer.
+func (e Entry) IssuerName() string {
+	if e.Name != "" {
+		return e.Name
 	}
+	return e.Issuer.String()
+}
 
-	for _, item := range items {
-		iinTrie.Put(item.Issuer, item.Prefix, item.Length)
+// IsTypicalLength checks if n is a common card number length for the range.
+func (e Entry) IsTypicalLength(n int) bool {
+	if e.TypicalLength == (IntRange{}) {
+		return true
 	}
+	return e.TypicalLength.Contains(n)
+}
+
+// EffectiveAt checks if the range is in use at t.
+func (e Entry) EffectiveAt(t time.Time) bool {
+	if !e.EffectiveFrom.IsZero() && t.Before(e.EffectiveFrom) {
+		return false
+	}
+	if !e.EffectiveUntil.IsZero() && !t.Before(e.EffectiveUntil) {
+		return false
+	}
+	return true
+}
+
+// defaultRegistry contains all known credit card issuers' identification numbers.
+var defaultRegistry = MustNewRegistry([]Entry{
+	{Issuer: AmericanExpress, Prefix: NewSingleIntRange(34), Length: NewSingleIntRange(15), Checksum: Luhn},
+	{Issuer: AmericanExpress, Prefix: NewSingleIntRange(37), Length: NewSingleIntRange(15), Checksum: Luhn},
+	{Issuer: DinersClub, Prefix: NewSingleIntRange(30), Length: NewSingleIntRange(14), Checksum: Luhn},
+	{Issuer: DinersClub, Prefix: NewSingleIntRange(36), Length: NewSingleIntRange(14), Checksum: Luhn},
+	{Issuer: DinersClub, Prefix: NewSingleIntRange(38), Length: NewSingleIntRange(14), Checksum: Luhn},
+	{Issuer: DinersClub, Prefix: NewSingleIntRange(39), Length: NewSingleIntRange(14), Checksum: Luhn},
+	{Issuer: Discover, Prefix: NewSingleIntRange(6011), Length: NewSingleIntRange(16), Checksum: Luhn},
+	{Issuer: Discover, Prefix: NewIntRange(644, 649), Length: NewSingleIntRange(16), Checksum: Luhn},
+	{Issuer: Discover, Prefix: NewSingleIntRange(65), Length: NewSingleIntRange(16), Checksum: Luhn},
+	{Issuer: JCB, Prefix: NewIntRange(3528, 3589), Length: NewSingleIntRange(16), Checksum: Luhn},
+	{Issuer: MasterCard, Prefix: NewIntRange(51, 55), Length: NewSingleIntRange(16), Checksum: Luhn},
+	{
+		Issuer:   MasterCard,
+		Prefix:   NewIntRange(2221, 2720),
+		Length:   NewSingleIntRange(16),
+		Checksum: Luhn,
+		// 2-series BINs went live in October 2016.
+		EffectiveFrom: time.Date(2016, time.October, 1, 0, 0, 0, 0, time.UTC),
+	},
+	{
+		Issuer:        UnionPay,
+		Prefix:        NewSingleIntRange(62),
+		Length:        NewIntRange(13, 19),
+		Checksum:      Luhn,
+		TypicalLength: NewSingleIntRange(16),
+	},
+	// 19-digit debit cards of the domestic 621 and 623 ranges may be issued without a Luhn check
+	// digit. Other 62 numbers, such as the 622126-622925 range co-branded with Discover, have one.
+	{Issuer: UnionPay, Prefix: NewSingleIntRange(621), Length: NewSingleIntRange(19), Checksum: NoChecksum},
+	{Issuer: UnionPay, Prefix: NewSingleIntRange(623), Length: NewSingleIntRange(19), Checksum: NoChecksum},
+	{Issuer: Visa, Prefix: NewSingleIntRange(4), Length: NewSingleIntRange(16), Checksum: Luhn},
+})
+
+// Default returns the built-in registry of known issuers.
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Version returns the version of the built-in registry.
+func Version() string {
+	return defaultRegistry.Version()
 }
 
 // Identify tries to identify the issuer of a given credit card number based on the
@@ -76,9 +139,32 @@ func init() {
 // It does not do any validation and assumes that cardNumber contains only ASCII digits and
 // may panic on non-digit characters.
 func Identify(cardNumber string) Issuer {
-	issuer, length := iinTrie.Get(cardNumber)
-	if length.Contains(len(cardNumber)) {
-		return issuer
+	entry, ok := Lookup(cardNumber)
+	if !ok {
+		return Unknown
 	}
-	return Unknown
+	return entry.Issuer
+}
+
+// Lookup returns the registry entry that matches cardNumber's IIN and length at the current time.
+// Just like Identify, it assumes that cardNumber contains only ASCII digits.
+func Lookup(cardNumber string) (Entry, bool) {
+	return defaultRegistry.LookupAt(cardNumber, time.Now().UTC())
+}
+
+// LookupAt is like Lookup but only considers ranges that are in use at t.
+func LookupAt(cardNumber string, t time.Time) (Entry, bool) {
+	return defaultRegistry.LookupAt(cardNumber, t)
+}
+
+// MatchAt returns built-in entries whose IIN matches prefix and that are in use at t,
+// see Registry.MatchAt.
+func MatchAt(prefix string, t time.Time) []Entry {
+	return defaultRegistry.MatchAt(prefix, t)
+}
+
+// LookupPrefixAt returns the built-in entry for card numbers of given length that start with
+// prefix, see Registry.LookupPrefixAt.
+func LookupPrefixAt(prefix string, length int, t time.Time) (Entry, bool) {
+	return defaultRegistry.LookupPrefixAt(prefix, length, t)
 }
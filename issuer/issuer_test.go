@@ -3,8 +3,10 @@ package issuer
 import (
 	_ "embed"
 	"encoding/csv"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 //go:embed valid-cards.csv
@@ -25,3 +27,224 @@ func TestIdentify(t *testing.T) {
 		}
 	}
 }
+
+func TestLookupChecksum(t *testing.T) {
+	tests := []struct {
+		cardNumber string
+		checksum   Checksum
+	}{
+		{"4111111111111111", Luhn},
+		{"378282246310005", Luhn},
+		{"62123456789000003", Luhn},
+		{"6212345678900000003", NoChecksum},
+		{"6230123456789000000", NoChecksum},
+		{"6221263456789000009", Luhn},
+		{"6282123456789000001", Luhn},
+	}
+
+	for _, tc := range tests {
+		entry, ok := Lookup(tc.cardNumber)
+		if !ok {
+			t.Errorf("no registry entry for %s", tc.cardNumber)
+			continue
+		}
+		if entry.Checksum.Name() != tc.checksum.Name() {
+			t.Errorf("checksum mismatch (%s): want %s have %s",
+				tc.cardNumber, tc.checksum, entry.Checksum)
+		}
+	}
+}
+
+func TestChecksumCompletions(t *testing.T) {
+	// A custom check that only accepts card numbers ending in an even digit.
+	even := NewChecksum("even", func(cardNumber string) bool {
+		return (cardNumber[len(cardNumber)-1]-'0')%2 == 0
+	})
+
+	tests := []struct {
+		checksum Checksum
+		pattern  string
+		want     uint64
+	}{
+		{Luhn, "4111111111111111", 1},
+		{Luhn, "411111******1111", 100000},
+		{NoChecksum, "411111******1111", 1000000},
+		{even, "41111111111111**", 50},
+		{even, "4111111111111111", 0},
+	}
+
+	for _, tc := range tests {
+		if n := tc.checksum.Completions(tc.pattern); n != tc.want {
+			t.Errorf("completions mismatch (%s, %s): want %d have %d", tc.checksum, tc.pattern, tc.want, n)
+		}
+	}
+}
+
+func TestBINDatabase(t *testing.T) {
+	db, err := LoadBINDatabase("testdata/bins.csv")
+	if err != nil {
+		t.Fatalf("error loading BIN database: %s", err)
+	}
+
+	tests := []struct {
+		cardNumber string
+		issuer     Issuer
+		bank       string
+		funding    string
+		country    string
+	}{
+		{"4111111111111111", Visa, "Test Bank Premium", FundingDebit, "US"},
+		{"4111112222222222", Visa, "Test Bank", FundingCredit, "US"},
+		{"5555555555554444", MasterCard, "Example Savings", FundingCredit, "GB"},
+		{"6212345678901265", UnionPay, "Sample UnionPay Bank", FundingDebit, "CN"},
+		{"4012888888881881", Visa, "", "", ""},
+	}
+
+	for _, tc := range tests {
+		entry, ok := Lookup(tc.cardNumber)
+		if !ok {
+			t.Errorf("no lookup result for %s", tc.cardNumber)
+			continue
+		}
+		d := db.Details(entry, tc.cardNumber)
+		if d.Issuer != tc.issuer || d.Bank != tc.bank || d.Funding != tc.funding || d.Country != tc.country {
+			t.Errorf("lookup mismatch (%s): have %+v", tc.cardNumber, d)
+		}
+	}
+}
+
+func TestBINDatabaseJSON(t *testing.T) {
+	db, err := LoadBINDatabase("testdata/bins.json")
+	if err != nil {
+		t.Fatalf("error loading BIN database: %s", err)
+	}
+	if db.Len() != 2 {
+		t.Fatalf("unexpected record count: want 2 have %d", db.Len())
+	}
+
+	info, ok := db.BIN("6011111111111117")
+	if !ok || info.Funding != FundingPrepaid {
+		t.Fatalf("unexpected BIN record: %+v", info)
+	}
+}
+
+func TestBINDatabaseColumns(t *testing.T) {
+	db, err := ParseBINCSV(strings.NewReader("Country,BIN,bank,level,funding,notes\nUS,411111,Test Bank,classic,credit,sandbox\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	info, ok := db.BIN("4111111111111111")
+	want := BINInfo{BIN: "411111", Funding: FundingCredit, Level: "classic", Bank: "Test Bank", Country: "US"}
+	if !ok || info != want {
+		t.Errorf("unexpected BIN record: %+v", info)
+	}
+}
+
+func TestBINDatabaseMalformed(t *testing.T) {
+	tests := []string{
+		"bin,funding,level,bank,country\n4111,credit,,,US\n",
+		"bin,funding,level,bank,country\n411111,charge,,,US\n",
+		"bin,funding,level,bank,country\n411111,credit,,,usa\n",
+		"bin,funding,level,bank,country\n411111,credit,,,12\n",
+		"bin,funding,level,bank,country\n411111,credit,,,--\n",
+		"bin,funding,level,bank,country\n411111,credit,,,us\n",
+		"bin,funding,level,bank,country\n411111,credit,,,US\n411111,debit,,,US\n",
+		"411111,credit,classic,Test Bank,US\n41111111,debit,platinum,Test Bank Premium,US\n",
+		"bin,funding,level,bank\n411111,credit,classic,Test Bank\n",
+		"bin,funding,level,bank,country\n411111,credit,classic,Test Bank\n",
+	}
+
+	for _, tc := range tests {
+		if _, err := ParseBINCSV(strings.NewReader(tc)); !errors.Is(err, ErrMalformedBINDatabase) {
+			t.Errorf("expected ErrMalformedBINDatabase for %q, got %v", tc, err)
+		}
+	}
+
+	var db *BINDatabase
+	if _, ok := db.BIN("4111111111111111"); ok {
+		t.Errorf("expected nil database to have no records")
+	}
+}
+
+func TestRegistryEffectiveDates(t *testing.T) {
+	cutover := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	reg, err := NewRegistry([]Entry{
+		{Issuer: Visa, Prefix: NewSingleIntRange(4), Length: NewSingleIntRange(16), EffectiveUntil: cutover},
+		{Issuer: MasterCard, Prefix: NewSingleIntRange(4), Length: NewSingleIntRange(16), EffectiveFrom: cutover},
+	})
+	if err != nil {
+		t.Fatalf("error building registry: %s", err)
+	}
+
+	if e, _ := reg.LookupAt("4111111111111111", cutover.Add(-time.Second)); e.Issuer != Visa {
+		t.Errorf("unexpected issuer before cutover: want %s have %s", Visa, e.Issuer)
+	}
+	if e, _ := reg.LookupAt("4111111111111111", cutover); e.Issuer != MasterCard {
+		t.Errorf("unexpected issuer after cutover: want %s have %s", MasterCard, e.Issuer)
+	}
+	if e := reg.Entries()[0]; e.Checksum.Name() != Luhn.Name() {
+		t.Errorf("expected checksum to default to luhn, got %q", e.Checksum)
+	}
+}
+
+func TestRegistryOverlap(t *testing.T) {
+	_, err := NewRegistry([]Entry{
+		{Issuer: Visa, Prefix: NewSingleIntRange(4), Length: NewIntRange(13, 16)},
+		{Issuer: MasterCard, Prefix: NewIntRange(40, 41), Length: NewSingleIntRange(16)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error for nested prefixes: %s", err)
+	}
+
+	_, err = NewRegistry([]Entry{
+		{Issuer: Visa, Prefix: NewSingleIntRange(4), Length: NewIntRange(13, 16)},
+		{Issuer: MasterCard, Prefix: NewSingleIntRange(4), Length: NewSingleIntRange(16)},
+	})
+	if !errors.Is(err, ErrOverlappingEntry) {
+		t.Fatalf("expected ErrOverlappingEntry, got %v", err)
+	}
+}
+
+func TestRegistryNestedPrefixes(t *testing.T) {
+	now := time.Now()
+	reg := MustNewRegistry([]Entry{
+		{Issuer: Visa, Prefix: NewSingleIntRange(4), Length: NewIntRange(13, 16)},
+		{Issuer: MasterCard, Prefix: NewIntRange(40, 41), Length: NewSingleIntRange(19)},
+	})
+	tests := []struct {
+		number string
+		want   Issuer
+	}{
+		{"4012888888881881123", MasterCard}, // Longer prefix matches on length.
+		{"4012888888881881", Visa},          // Falls back to the shorter one.
+		{"4222222222222", Visa},
+		{"4222222222222222222", Unknown},
+	}
+	for _, tc := range tests {
+		if e, _ := reg.LookupAt(tc.number, now); e.Issuer != tc.want {
+			t.Errorf("%s: want %s have %s", tc.number, tc.want, e.Issuer)
+		}
+	}
+
+	// A retired short range doesn't shadow a longer one that is still in use.
+	reg = MustNewRegistry([]Entry{
+		{Issuer: Visa, Prefix: NewSingleIntRange(4), Length: NewSingleIntRange(16), EffectiveUntil: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Issuer: MasterCard, Prefix: NewSingleIntRange(41), Length: NewSingleIntRange(16)},
+	})
+	if e, ok := reg.LookupAt("4111111111111111", now); !ok || e.Issuer != MasterCard {
+		t.Errorf("retired range: want %s have %s", MasterCard, e.Issuer)
+	}
+	if e, ok := reg.LookupAt("4211111111111111", now); ok {
+		t.Errorf("retired range: unexpected match %s", e.Issuer)
+	}
+}
+
+func TestRegistryVersion(t *testing.T) {
+	entries := Default().Entries()
+	if MustNewRegistry(entries).Version() != Version() {
+		t.Errorf("expected equal registries to have equal versions")
+	}
+	if MustNewRegistry(entries[1:]).Version() == Version() {
+		t.Errorf("expected different registries to have different versions")
+	}
+}
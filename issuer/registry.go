@@ -0,0 +1,113 @@
+package issuer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrOverlappingEntry is returned when a registry entry clashes with an existing one.
+var ErrOverlappingEntry = errors.New("issuer: overlapping registry entry")
+
+// Registry is an immutable, versioned table of IIN ranges.
+type Registry struct {
+	trie    *trie
+	entries []Entry
+	version string
+}
+
+// NewRegistry builds a registry from entries. Entries under the same prefix must not have
+// overlapping card number lengths while their effective periods overlap.
+func NewRegistry(entries []Entry) (*Registry, error) {
+	reg := &Registry{
+		trie:    newTrie(),
+		entries: make([]Entry, 0, len(entries)),
+	}
+
+	h := sha256.New()
+	for _, entry := range entries {
+		if entry.Checksum.Name() == "" {
+			entry.Checksum = Luhn
+		}
+		if err := reg.trie.Put(entry); err != nil {
+			return nil, err
+		}
+		reg.entries = append(reg.entries, entry)
+
+		fmt.Fprintf(h, "%d|%s|%d-%d|%d-%d|%d-%d|%s|%s|%s\n",
+			entry.Issuer, entry.Name, entry.Prefix.Start, entry.Prefix.End,
+			entry.Length.Start, entry.Length.End, entry.TypicalLength.Start, entry.TypicalLength.End,
+			entry.Checksum.Name(),
+			formatDate(entry.EffectiveFrom), formatDate(entry.EffectiveUntil))
+	}
+	reg.version = hex.EncodeToString(h.Sum(nil))[:16]
+
+	return reg, nil
+}
+
+// MustNewRegistry is like NewRegistry but panics on error.
+func MustNewRegistry(entries []Entry) *Registry {
+	reg, err := NewRegistry(entries)
+	if err != nil {
+		panic(err)
+	}
+	return reg
+}
+
+// Version returns a checksum of the registry's contents. Two registries with the same entries
+// in the same order have equal versions.
+func (r *Registry) Version() string {
+	return r.version
+}
+
+// Entries returns a copy of the registry's entries.
+func (r *Registry) Entries() []Entry {
+	return append([]Entry(nil), r.entries...)
+}
+
+// MatchAt returns entries whose IIN matches prefix and that are in use at t regardless of
+// card number length, longest IIN first. prefix has to be long enough to cover the IIN,
+// otherwise nothing matches.
+// It assumes that prefix contains only ASCII digits.
+func (r *Registry) MatchAt(prefix string, t time.Time) []Entry {
+	var entries []Entry
+	for _, entry := range r.trie.Get(prefix) {
+		if entry.EffectiveAt(t) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// LookupAt returns the entry that matches cardNumber's IIN and length and is in use at t. When
+// nested IIN ranges match, the longest one wins.
+// It assumes that cardNumber contains only ASCII digits.
+func (r *Registry) LookupAt(cardNumber string, t time.Time) (Entry, bool) {
+	return r.lookupLengthAt(cardNumber, len(cardNumber), t)
+}
+
+// LookupPrefixAt is like LookupAt but for card numbers of given length of which only the
+// leading digits in prefix are known.
+func (r *Registry) LookupPrefixAt(prefix string, length int, t time.Time) (Entry, bool) {
+	return r.lookupLengthAt(prefix, length, t)
+}
+
+// lookupLengthAt returns the entry that matches prefix and length and is in use at t.
+func (r *Registry) lookupLengthAt(prefix string, length int, t time.Time) (Entry, bool) {
+	for _, entry := range r.MatchAt(prefix, t) {
+		if entry.Length.Contains(length) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// formatDate formats t for the registry version checksum.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
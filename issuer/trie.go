@@ -1,41 +1,49 @@
 package issuer
 
-import "strconv"
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
 
-// intRange is helper for storing numeric ranges.
-type intRange struct {
-	Start int
-	End   int
+// IntRange is helper for storing numeric ranges.
+type IntRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
 }
 
-// newIntRange returns a new inRange.
-func newIntRange(start, end int) intRange {
-	return intRange{
+// NewIntRange returns a new IntRange.
+func NewIntRange(start, end int) IntRange {
+	return IntRange{
 		Start: start,
 		End:   end,
 	}
 }
 
-// newSingleIntRange returns a new intRange where Start is equal to End.
-func newSingleIntRange(start int) intRange {
-	return intRange{
+// NewSingleIntRange returns a new IntRange where Start is equal to End.
+func NewSingleIntRange(start int) IntRange {
+	return IntRange{
 		Start: start,
 		End:   start,
 	}
 }
 
 // Contains checks if n fits inside the range.
-func (r intRange) Contains(n int) bool {
+func (r IntRange) Contains(n int) bool {
 	return r.Start <= n && n <= r.End
 }
 
+// Overlaps checks if r and other have at least one number in common.
+func (r IntRange) Overlaps(other IntRange) bool {
+	return r.Start <= other.End && other.Start <= r.End
+}
+
 // trie is a prefix-tree structure that acts as a mapping of card number prefixes to issuers.
 // Since credit card numbers are composed of digits only, we use an array instead of a map for
 // trie's children where leaf index is ASCII digit - '0', therefore Get will panic on out-of-bounds
 // access if called with a key that contains non-digit characters.
 type trie struct {
-	issuer   Issuer
-	length   intRange
+	entries  []Entry
 	children [10]*trie
 }
 
@@ -44,32 +52,35 @@ func newTrie() *trie {
 	return &trie{}
 }
 
-// Get takes a credit card number and returns Issuer that matched its prefix plus the valid
-// card number length range for this IIN.
-func (t *trie) Get(key string) (Issuer, intRange) {
+// Get takes a credit card number and returns registry entries of all prefixes it starts with,
+// longest prefix first, so that callers filtering them by length or date fall back to shorter
+// prefixes. Entries under the same prefix differ by card number length.
+func (t *trie) Get(key string) []Entry {
+	var entries []Entry
 	node := t
 	for _, r := range key {
 		node = node.children[r-'0']
 		if node == nil {
-			return Unknown, intRange{}
-		}
-
-		if node.issuer != Unknown {
 			break
 		}
+		entries = slices.Insert(entries, 0, node.entries...)
 	}
-	return node.issuer, node.length
+	return entries
 }
 
-// Put adds a new IIN into the trie.
-func (t *trie) Put(issuer Issuer, prefix, length intRange) {
-	for key := prefix.Start; key <= prefix.End; key++ {
-		t.put(strconv.Itoa(key), issuer, length)
+// Put adds a new IIN range into the trie.
+func (t *trie) Put(entry Entry) error {
+	for key := entry.Prefix.Start; key <= entry.Prefix.End; key++ {
+		if err := t.put(strconv.Itoa(key), entry); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-// put adds a new leaf for given key into the trie and panics if it's a duplicate.
-func (t *trie) put(key string, issuer Issuer, length intRange) {
+// put adds a new leaf for given key into the trie and returns an error if it clashes with
+// an existing entry.
+func (t *trie) put(key string, entry Entry) error {
 	node := t
 	for _, r := range key {
 		child := node.children[r-'0']
@@ -79,9 +90,18 @@ func (t *trie) put(key string, issuer Issuer, length intRange) {
 		}
 		node = child
 	}
-	if node.issuer != Unknown {
-		panic("duplicate trie entry")
+	for _, e := range node.entries {
+		if e.Length.Overlaps(entry.Length) && periodsOverlap(e, entry) {
+			return fmt.Errorf("%w: %s %s", ErrOverlappingEntry, entry.Issuer, key)
+		}
 	}
-	node.issuer = issuer
-	node.length = length
+	node.entries = append(node.entries, entry)
+	return nil
+}
+
+// periodsOverlap checks if effective periods of a and b have at least one instant in common.
+func periodsOverlap(a, b Entry) bool {
+	aEndsBefore := !a.EffectiveUntil.IsZero() && !b.EffectiveFrom.IsZero() && !a.EffectiveUntil.After(b.EffectiveFrom)
+	bEndsBefore := !b.EffectiveUntil.IsZero() && !a.EffectiveFrom.IsZero() && !b.EffectiveUntil.After(a.EffectiveFrom)
+	return !aEndsBefore && !bEndsBefore
 }
@@ -0,0 +1,154 @@
+// Package jwe decrypts compact JSON Web Encryption payloads (RFC 7516) made with RSA-OAEP-256
+// key encryption and A256GCM content encryption, so clients can send card data that stays
+// encrypted until it reaches the server.
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrMalformed   = errors.New("jwe: malformed payload")
+	ErrUnsupported = errors.New("jwe: unsupported algorithm")
+	ErrUnknownKey  = errors.New("jwe: unknown key ID")
+	ErrDecryption  = errors.New("jwe: decryption failed")
+)
+
+// Supported algorithms.
+const (
+	AlgRSAOAEP256 = "RSA-OAEP-256"
+	EncA256GCM    = "A256GCM"
+)
+
+// cekSize is the content encryption key size of A256GCM.
+const cekSize = 32
+
+// header is a JWE protected header.
+type header struct {
+	Alg  string   `json:"alg"`
+	Enc  string   `json:"enc"`
+	Kid  string   `json:"kid,omitempty"`
+	Zip  string   `json:"zip,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// Encrypt encrypts plaintext to key as a compact JWE with key ID kid, which may be empty.
+func Encrypt(key *rsa.PublicKey, kid string, plaintext []byte) (string, error) {
+	h, err := json.Marshal(header{Alg: AlgRSAOAEP256, Enc: EncA256GCM, Kid: kid})
+	if err != nil {
+		return "", err
+	}
+	protected := base64.RawURLEncoding.EncodeToString(h)
+
+	cek := make([]byte, cekSize)
+	if _, err := io.ReadFull(rand.Reader, cek); err != nil {
+		return "", err
+	}
+	defer clear(cek)
+
+	encryptedKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, key, cek, nil)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	ciphertext, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return strings.Join([]string{
+		protected,
+		base64.RawURLEncoding.EncodeToString(encryptedKey),
+		base64.RawURLEncoding.EncodeToString(iv),
+		base64.RawURLEncoding.EncodeToString(ciphertext),
+		base64.RawURLEncoding.EncodeToString(tag),
+	}, "."), nil
+}
+
+// Decrypt decrypts a compact JWE with the key from keys that matches its kid header. Payloads
+// without a kid are tried with every key.
+func Decrypt(keys *KeySet, compact string) ([]byte, error) {
+	parts := strings.Split(strings.TrimSpace(compact), ".")
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("%w: expected 5 parts, got %d", ErrMalformed, len(parts))
+	}
+
+	var decoded [5][]byte
+	for i, p := range parts {
+		b, err := base64.RawURLEncoding.DecodeString(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w: part %d: %s", ErrMalformed, i+1, err)
+		}
+		decoded[i] = b
+	}
+	rawHeader, encryptedKey, iv, ciphertext, tag := decoded[0], decoded[1], decoded[2], decoded[3], decoded[4]
+
+	var h header
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, fmt.Errorf("%w: header: %s", ErrMalformed, err)
+	}
+	switch {
+	case h.Alg != AlgRSAOAEP256:
+		return nil, fmt.Errorf("%w: alg %q", ErrUnsupported, h.Alg)
+	case h.Enc != EncA256GCM:
+		return nil, fmt.Errorf("%w: enc %q", ErrUnsupported, h.Enc)
+	case h.Zip != "":
+		return nil, fmt.Errorf("%w: zip %q", ErrUnsupported, h.Zip)
+	case len(h.Crit) > 0:
+		return nil, fmt.Errorf("%w: critical header %q", ErrUnsupported, h.Crit[0])
+	}
+
+	candidates := keys.keys
+	if h.Kid != "" {
+		key, ok := keys.Key(h.Kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownKey, h.Kid)
+		}
+		candidates = []Key{key}
+	}
+
+	for _, key := range candidates {
+		cek, err := rsa.DecryptOAEP(sha256.New(), nil, key.PrivateKey, encryptedKey, nil)
+		if err != nil || len(cek) != cekSize {
+			continue
+		}
+		gcm, err := newGCM(cek)
+		clear(cek)
+		if err != nil || len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+			return nil, ErrDecryption
+		}
+
+		// The protected header is authenticated in its encoded form.
+		plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+		if err != nil {
+			return nil, ErrDecryption
+		}
+		return plaintext, nil
+	}
+	return nil, ErrDecryption
+}
+
+// newGCM returns an AES-GCM AEAD with key cek.
+func newGCM(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
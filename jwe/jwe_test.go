@@ -0,0 +1,203 @@
+package jwe
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	keysOnce sync.Once
+	testKeys [2]*rsa.PrivateKey
+)
+
+// rsaKeys returns two RSA keys shared by tests, generating them is slow.
+func rsaKeys(t *testing.T) [2]*rsa.PrivateKey {
+	keysOnce.Do(func() {
+		for i := range testKeys {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				t.Fatalf("error generating RSA key: %s", err)
+			}
+			testKeys[i] = key
+		}
+	})
+	return testKeys
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	keys := rsaKeys(t)
+	ks, err := NewKeySet(Key{ID: "new", PrivateKey: keys[1]}, Key{ID: "old", PrivateKey: keys[0]})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	plaintext := []byte(`{"number":"4111111111111111","exp_date":"08/2028"}`)
+	for _, tc := range []struct {
+		key *rsa.PrivateKey
+		kid string
+	}{
+		{keys[1], "new"},
+		{keys[0], "old"}, // Payloads encrypted before the rotation still work.
+		{keys[0], ""},    // Without kid every key is tried.
+	} {
+		compact, err := Encrypt(&tc.key.PublicKey, tc.kid, plaintext)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if strings.Contains(compact, "4111") || strings.Count(compact, ".") != 4 {
+			t.Errorf("unexpected compact serialization: %s", compact)
+		}
+
+		got, err := Decrypt(ks, compact)
+		if err != nil {
+			t.Fatalf("kid %q: unexpected error: %s", tc.kid, err)
+		}
+		if string(got) != string(plaintext) {
+			t.Errorf("kid %q: unexpected plaintext: %s", tc.kid, got)
+		}
+	}
+}
+
+func TestDecryptErrors(t *testing.T) {
+	keys := rsaKeys(t)
+	ks, _ := NewKeySet(Key{ID: "k1", PrivateKey: keys[0]})
+	other, _ := NewKeySet(Key{ID: "k2", PrivateKey: keys[1]})
+
+	compact, err := Encrypt(&keys[0].PublicKey, "k1", []byte("secret"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	parts := strings.Split(compact, ".")
+
+	// tampered replaces part i of the payload.
+	tampered := func(i int, part string) string {
+		p := append([]string(nil), parts...)
+		p[i] = part
+		return strings.Join(p, ".")
+	}
+	encodeHeader := func(h string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(h))
+	}
+	flipped := []byte(parts[3])
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+
+	cases := []struct {
+		name    string
+		keys    *KeySet
+		compact string
+		err     error
+	}{
+		{"parts", ks, "a.b.c", ErrMalformed},
+		{"base64", ks, tampered(1, "!!"), ErrMalformed},
+		{"alg", ks, tampered(0, encodeHeader(`{"alg":"RSA1_5","enc":"A256GCM"}`)), ErrUnsupported},
+		{"enc", ks, tampered(0, encodeHeader(`{"alg":"RSA-OAEP-256","enc":"A128CBC-HS256"}`)), ErrUnsupported},
+		{"zip", ks, tampered(0, encodeHeader(`{"alg":"RSA-OAEP-256","enc":"A256GCM","zip":"DEF"}`)), ErrUnsupported},
+		{"kid", other, compact, ErrUnknownKey},
+		// The header is authenticated, so changing it breaks decryption.
+		{"header", ks, tampered(0, encodeHeader(`{"alg":"RSA-OAEP-256","enc":"A256GCM"}`)), ErrDecryption},
+		{"ciphertext", ks, tampered(3, string(flipped)), ErrDecryption},
+		{"wrong key", other, tampered(0, encodeHeader(`{"alg":"RSA-OAEP-256","enc":"A256GCM"}`)), ErrDecryption},
+	}
+	for _, c := range cases {
+		if _, err := Decrypt(c.keys, c.compact); !errors.Is(err, c.err) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	keys := rsaKeys(t)
+	ks, _ := NewKeySet(Key{ID: "new", PrivateKey: keys[1]}, Key{ID: "old", PrivateKey: keys[0]})
+
+	jwks := ks.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "new" || jwks.Keys[1].Kid != "old" {
+		t.Fatalf("unexpected key set: %+v", jwks)
+	}
+	jwk := jwks.Keys[0]
+	if jwk.Kty != "RSA" || jwk.Use != "enc" || jwk.Alg != AlgRSAOAEP256 || jwk.E != "AQAB" {
+		t.Errorf("unexpected JWK: %+v", jwk)
+	}
+
+	// A client can encrypt to the published key.
+	pub, err := jwk.PublicKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !pub.Equal(&keys[1].PublicKey) {
+		t.Error("published key doesn't match the private key")
+	}
+	compact, _ := Encrypt(pub, jwk.Kid, []byte("hello"))
+	if got, err := Decrypt(ks, compact); err != nil || string(got) != "hello" {
+		t.Errorf("Decrypt = %q, %v", got, err)
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	keys := rsaKeys(t)
+	dir := t.TempDir()
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]*pem.Block{
+		"2024-01.pem": {Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(keys[0])},
+		"2025-01.pem": {Type: "PRIVATE KEY", Bytes: pkcs8},
+	}
+	for name, block := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ks, err := LoadKeySet(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if ks.Active().ID != "2025-01" {
+		t.Errorf("unexpected active key: %s", ks.Active().ID)
+	}
+	if k, ok := ks.Key("2024-01"); !ok || !k.PrivateKey.Equal(keys[0]) {
+		t.Error("expected the older key to be loaded")
+	}
+
+	if _, err := LoadKeySet(t.TempDir()); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for an empty directory, got %v", err)
+	}
+	if _, err := ParsePrivateKey([]byte("not a key")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestNewKeySetErrors(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := rsaKeys(t)
+
+	cases := [][]Key{
+		nil,
+		{{ID: "", PrivateKey: keys[0]}},
+		{{ID: "k", PrivateKey: keys[0]}, {ID: "k", PrivateKey: keys[1]}},
+		{{ID: "k", PrivateKey: small}},
+	}
+	for i, c := range cases {
+		if _, err := NewKeySet(c...); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("case %d: expected ErrInvalidKey, got %v", i, err)
+		}
+	}
+}
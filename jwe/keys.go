@@ -0,0 +1,178 @@
+package jwe
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("jwe: invalid key")
+
+// minKeyBits is the smallest accepted RSA modulus size.
+const minKeyBits = 2048
+
+// Key is an RSA decryption key with its key ID.
+type Key struct {
+	ID         string
+	PrivateKey *rsa.PrivateKey
+}
+
+// KeySet holds the server's decryption keys. All of them are accepted for decryption and
+// published, the active one is listed first so that clients pick it for new payloads.
+type KeySet struct {
+	keys []Key // Active key first.
+}
+
+// NewKeySet returns a key set with keys, the first of which is the active one.
+func NewKeySet(keys ...Key) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidKey)
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, fmt.Errorf("%w: missing key ID", ErrInvalidKey)
+		}
+		if seen[k.ID] {
+			return nil, fmt.Errorf("%w: duplicate key ID %q", ErrInvalidKey, k.ID)
+		}
+		seen[k.ID] = true
+		if k.PrivateKey == nil || k.PrivateKey.N.BitLen() < minKeyBits {
+			return nil, fmt.Errorf("%w: %s: RSA key must be at least %d bits", ErrInvalidKey, k.ID, minKeyBits)
+		}
+	}
+	return &KeySet{keys: slices.Clone(keys)}, nil
+}
+
+// LoadKeySet loads PEM-encoded RSA private keys from *.pem files in dir. Each key's ID is its
+// file name without the extension. Keys are ordered by ID, the one that sorts last is the
+// active one, e.g. with date-based IDs like 2025-01 the newest key is active.
+func LoadKeySet(dir string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	slices.Reverse(paths)
+
+	keys := make([]Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := ParsePrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, Key{
+			ID:         strings.TrimSuffix(filepath.Base(path), ".pem"),
+			PrivateKey: priv,
+		})
+	}
+	return NewKeySet(keys...)
+}
+
+// ParsePrivateKey parses a PEM-encoded RSA private key in PKCS #1 or PKCS #8 form.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM data", ErrInvalidKey)
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: not an RSA key", ErrInvalidKey)
+		}
+		return rsaKey, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected PEM block %q", ErrInvalidKey, block.Type)
+	}
+}
+
+// Active returns the key clients should encrypt new payloads to.
+func (s *KeySet) Active() Key {
+	return s.keys[0]
+}
+
+// Key returns the key with ID kid.
+func (s *KeySet) Key(kid string) (Key, bool) {
+	i := slices.IndexFunc(s.keys, func(k Key) bool { return k.ID == kid })
+	if i < 0 {
+		return Key{}, false
+	}
+	return s.keys[i], true
+}
+
+// JWK is a public RSA key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of s, active key first.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		pub := k.PrivateKey.PublicKey
+		jwks.Keys = append(jwks.Keys, JWK{
+			Kty: "RSA",
+			Kid: k.ID,
+			Use: "enc",
+			Alg: AlgRSAOAEP256,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		})
+	}
+	return jwks
+}
+
+// PublicKey returns the RSA public key described by k.
+func (k JWK) PublicKey() (*rsa.PublicKey, error) {
+	if k.Kty != "RSA" {
+		return nil, fmt.Errorf("%w: key type %q", ErrInvalidKey, k.Kty)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("%w: n: %s", ErrInvalidKey, err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("%w: e: %s", ErrInvalidKey, err)
+	}
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("%w: invalid exponent", ErrInvalidKey)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
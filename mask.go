@@ -0,0 +1,28 @@
+package cardvalidate
+
+import "strings"
+
+// MaskPAN hides all but the first 6 and the last 4 digits of pan, as allowed by PCI DSS,
+// e.g. 411111******1111. Card numbers shorter than 13 digits keep only the last 4 digits.
+func MaskPAN(pan string) string {
+	return maskDigits([]byte(pan))
+}
+
+// maskDigits is like MaskPAN but takes the card number as bytes, which are left untouched.
+func maskDigits(pan []byte) string {
+	if len(pan) <= 4 {
+		return strings.Repeat("*", len(pan))
+	}
+
+	first := 6
+	if len(pan) < 13 {
+		first = 0
+	}
+
+	var b strings.Builder
+	b.Grow(len(pan))
+	b.Write(pan[:first])
+	b.WriteString(strings.Repeat("*", len(pan)-first-4))
+	b.Write(pan[len(pan)-4:])
+	return b.String()
+}
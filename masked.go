@@ -0,0 +1,110 @@
+package cardvalidate
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+var (
+	ErrMalformedMaskedPAN = errors.New("cardvalidate: malformed masked card number")
+	ErrImplausibleLength  = errors.New("cardvalidate: card number length is implausible for issuer")
+)
+
+// maskChar replaces hidden digits in MaskedPAN's canonical form.
+const maskChar = '*'
+
+// MaskedPAN is a card number with some of its digits hidden, such as 411111******1111.
+type MaskedPAN struct {
+	pattern string // Canonical form with hidden digits replaced by maskChar.
+}
+
+// ParseMaskedPAN parses a masked card number. Hidden digits may be marked with '*', 'X', 'x'
+// or '#', and the leading digits have to be visible.
+func ParseMaskedPAN(s string) (MaskedPAN, error) {
+	if len(s) < 8 || len(s) > 19 {
+		return MaskedPAN{}, ErrMalformedMaskedPAN
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case isDigit(r):
+			b.WriteRune(r)
+		case r == '*' || r == 'X' || r == 'x' || r == '#':
+			b.WriteByte(maskChar)
+		default:
+			return MaskedPAN{}, ErrMalformedMaskedPAN
+		}
+	}
+
+	m := MaskedPAN{pattern: b.String()}
+	if m.BIN() == "" {
+		return MaskedPAN{}, ErrMalformedMaskedPAN
+	}
+	return m, nil
+}
+
+// String returns the masked card number with hidden digits replaced by '*'.
+func (m MaskedPAN) String() string {
+	return m.pattern
+}
+
+// Len returns the length of the full card number.
+func (m MaskedPAN) Len() int {
+	return len(m.pattern)
+}
+
+// BIN returns the leading visible digits.
+func (m MaskedPAN) BIN() string {
+	if i := strings.IndexByte(m.pattern, maskChar); i >= 0 {
+		return m.pattern[:i]
+	}
+	return m.pattern
+}
+
+// LastDigits returns the trailing visible digits.
+func (m MaskedPAN) LastDigits() string {
+	return m.pattern[strings.LastIndexByte(m.pattern, maskChar)+1:]
+}
+
+// Lookup identifies the issuer from the visible BIN and checks that the masked length is
+// plausible for it. It returns ErrUnknownIssuer if the BIN doesn't match any issuer and
+// ErrImplausibleLength if it does but not for this length.
+func (m MaskedPAN) Lookup() (issuer.Entry, error) {
+	now := time.Now().UTC()
+	if entry, ok := issuer.LookupPrefixAt(m.BIN(), m.Len(), now); ok {
+		return entry, nil
+	}
+	if len(issuer.MatchAt(m.BIN(), now)) > 0 {
+		return issuer.Entry{}, ErrImplausibleLength
+	}
+	return issuer.Entry{}, ErrUnknownIssuer
+}
+
+// Matches checks if pan could be the full card number behind m, i.e. it has the same length
+// and the same visible digits.
+func (m MaskedPAN) Matches(pan string) bool {
+	if len(pan) != len(m.pattern) {
+		return false
+	}
+	for i := 0; i < len(pan); i++ {
+		if m.pattern[i] != maskChar && m.pattern[i] != pan[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Completions returns the number of card numbers behind m that pass the checksum of m's issuer,
+// or Luhn's check if the issuer is unknown.
+func (m MaskedPAN) Completions() uint64 {
+	checksum := issuer.Luhn
+	if entry, err := m.Lookup(); err == nil {
+		checksum = entry.Checksum
+	}
+	return checksum.Completions(m.pattern)
+}
@@ -0,0 +1,117 @@
+package cardvalidate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+func TestParseMaskedPAN(t *testing.T) {
+	tests := []struct {
+		masked      string
+		canonical   string
+		bin         string
+		last        string
+		completions uint64
+	}{
+		{"411111******1111", "411111******1111", "411111", "1111", 100000},
+		{"411111XXXXXX1111", "411111******1111", "411111", "1111", 100000},
+		{"37828224631000#", "37828224631000*", "37828224631000", "", 1},
+		{"4111111111111111", "4111111111111111", "4111111111111111", "4111111111111111", 1},
+		// 19-digit UnionPay numbers don't have a check digit.
+		{"621234*********0003", "621234*********0003", "621234", "0003", 1000000000},
+		{"621234*******0003", "621234*******0003", "621234", "0003", 1000000},
+	}
+
+	for _, tc := range tests {
+		m, err := ParseMaskedPAN(tc.masked)
+		if err != nil {
+			t.Errorf("unexpected error parsing %s: %s", tc.masked, err)
+			continue
+		}
+		if m.String() != tc.canonical || m.BIN() != tc.bin || m.LastDigits() != tc.last {
+			t.Errorf("parse mismatch (%s): have %s, BIN %s, last digits %s",
+				tc.masked, m, m.BIN(), m.LastDigits())
+		}
+		if n := m.Completions(); n != tc.completions {
+			t.Errorf("completions mismatch (%s): want %d have %d", tc.masked, tc.completions, n)
+		}
+	}
+
+	for _, s := range []string{"", "****1111", "4111-1111-1111-1111", "411111******11111111"} {
+		if _, err := ParseMaskedPAN(s); !errors.Is(err, ErrMalformedMaskedPAN) {
+			t.Errorf("expected ErrMalformedMaskedPAN for %q, got %v", s, err)
+		}
+	}
+}
+
+func TestMaskedPANLookup(t *testing.T) {
+	tests := []struct {
+		masked string
+		issuer issuer.Issuer
+		err    error
+	}{
+		{"411111******1111", issuer.Visa, nil},
+		{"3782*********05", issuer.AmericanExpress, nil},
+		{"411111*******1111", issuer.Unknown, ErrImplausibleLength},
+		{"911111******1111", issuer.Unknown, ErrUnknownIssuer},
+		// The visible BIN is too short to tell the issuer.
+		{"3*************11", issuer.Unknown, ErrUnknownIssuer},
+	}
+
+	for _, tc := range tests {
+		m, err := ParseMaskedPAN(tc.masked)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %s", tc.masked, err)
+		}
+		entry, err := m.Lookup()
+		if !errors.Is(err, tc.err) || entry.Issuer != tc.issuer {
+			t.Errorf("lookup mismatch (%s): want %s/%v have %s/%v", tc.masked, tc.issuer, tc.err, entry.Issuer, err)
+		}
+	}
+}
+
+func TestMaskedPANMatches(t *testing.T) {
+	m, err := ParseMaskedPAN("411111******1111")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		pan  string
+		want bool
+	}{
+		{"4111111111111111", true},
+		{"4111119876541111", true},
+		{"4111121111111111", false},
+		{"4111111111111112", false},
+		{"411111111111111", false},
+	}
+
+	for _, tc := range tests {
+		if have := m.Matches(tc.pan); have != tc.want {
+			t.Errorf("Matches(%s): want %t have %t", tc.pan, tc.want, have)
+		}
+	}
+}
+
+func TestMaskPAN(t *testing.T) {
+	tests := []struct {
+		pan  string
+		want string
+	}{
+		{"4111111111111111", "411111******1111"},
+		{"378282246310005", "378282*****0005"},
+		{"6212345678900000003", "621234*********0003"},
+		{"12345678", "****5678"},
+		{"123", "***"},
+		{"", ""},
+	}
+
+	for _, tc := range tests {
+		if have := MaskPAN(tc.pan); have != tc.want {
+			t.Errorf("MaskPAN(%s): want %s have %s", tc.pan, tc.want, have)
+		}
+	}
+}
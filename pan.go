@@ -0,0 +1,107 @@
+package cardvalidate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// PAN is a primary account number that never reveals its digits when it's printed, marshalled
+// or logged: fmt verbs, JSON and text encodings and slog all see the masked form produced by
+// MaskPAN. The digits are only available through Bytes and Raw.
+// The zero value is an empty card number.
+type PAN struct {
+	digits []byte
+}
+
+// NewPAN returns a PAN holding a copy of cardNumber. It does not validate cardNumber.
+func NewPAN(cardNumber string) PAN {
+	return PAN{digits: []byte(cardNumber)}
+}
+
+// Raw returns the card number digits. The returned string is a copy that is not affected by Zero,
+// so it should be kept no longer than necessary. Prefer Bytes where a byte slice will do.
+func (p PAN) Raw() string {
+	return string(p.digits)
+}
+
+// Bytes returns the card number digits. The returned slice shares memory with p, so it's wiped
+// by Zero, and must not be modified.
+func (p PAN) Bytes() []byte {
+	return p.digits
+}
+
+// Len returns the number of digits in p.
+func (p PAN) Len() int {
+	return len(p.digits)
+}
+
+// Masked returns p with all but the first 6 and the last 4 digits masked, see MaskPAN.
+func (p PAN) Masked() string {
+	return maskDigits(p.digits)
+}
+
+// Zero overwrites the card number digits in memory and empties p. Copies of p share the same
+// memory and become zeroed as well.
+func (p *PAN) Zero() {
+	clear(p.digits)
+	p.digits = nil
+}
+
+// String implements fmt.Stringer and returns the masked card number.
+func (p PAN) String() string {
+	return p.Masked()
+}
+
+// GoString implements fmt.GoStringer and returns the masked card number.
+func (p PAN) GoString() string {
+	return fmt.Sprintf("cardvalidate.PAN(%q)", p.Masked())
+}
+
+// Format implements fmt.Formatter so that every verb, including %x and %d, formats the
+// masked card number.
+func (p PAN) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if f.Flag('#') {
+			io.WriteString(f, p.GoString())
+			return
+		}
+	case 'q':
+		fmt.Fprintf(f, fmt.FormatString(f, verb), p.Masked())
+		return
+	}
+	fmt.Fprintf(f, fmt.FormatString(f, 's'), p.Masked())
+}
+
+// MarshalJSON implements json.Marshaler and encodes the masked card number.
+func (p PAN) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Masked())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The card number must be a JSON string.
+func (p *PAN) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*p = NewPAN(s)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler and encodes the masked card number.
+func (p PAN) MarshalText() ([]byte, error) {
+	return []byte(p.Masked()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *PAN) UnmarshalText(text []byte) error {
+	p.digits = append([]byte(nil), text...)
+	return nil
+}
+
+// LogValue implements slog.LogValuer and logs the masked card number.
+func (p PAN) LogValue() slog.Value {
+	return slog.StringValue(p.Masked())
+}
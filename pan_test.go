@@ -0,0 +1,136 @@
+package cardvalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+func TestPANFormatting(t *testing.T) {
+	pan := NewPAN("4111111111111111")
+	const masked = "411111******1111"
+
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{"%v", masked},
+		{"%s", masked},
+		{"%d", masked},
+		{"%x", masked},
+		{"%q", `"` + masked + `"`},
+		{"%20s", "    " + masked},
+		{"%+v", masked},
+		{"%#v", `cardvalidate.PAN("` + masked + `")`},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprintf(c.format, pan); got != c.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", c.format, got, c.want)
+		}
+	}
+
+	// Card numbers nested in other values are masked as well.
+	s := struct{ Number PAN }{pan}
+	if got := fmt.Sprintf("%+v", s); got != "{Number:"+masked+"}" {
+		t.Errorf("unexpected nested formatting: %s", got)
+	}
+	if got := fmt.Sprintf("%v", &pan); strings.Contains(got, "4111111111111111") {
+		t.Errorf("pointer formatting leaks the card number: %s", got)
+	}
+}
+
+func TestPANEncoding(t *testing.T) {
+	pan := NewPAN("378282246310005")
+
+	b, err := json.Marshal(map[string]PAN{"number": pan})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(b) != `{"number":"378282*****0005"}` {
+		t.Errorf("unexpected JSON: %s", b)
+	}
+
+	text, err := pan.MarshalText()
+	if err != nil || string(text) != "378282*****0005" {
+		t.Errorf("unexpected text: %s, %v", text, err)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("charge", "pan", pan)
+	if !strings.Contains(buf.String(), `"pan":"378282*****0005"`) {
+		t.Errorf("unexpected log output: %s", buf.String())
+	}
+}
+
+func TestPANDecoding(t *testing.T) {
+	var req struct {
+		Number PAN `json:"number"`
+	}
+	if err := json.Unmarshal([]byte(`{"number":"4111111111111111"}`), &req); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if req.Number.Raw() != "4111111111111111" {
+		t.Errorf("unexpected card number: %s", req.Number)
+	}
+
+	if err := json.Unmarshal([]byte(`{"number":4111111111111111}`), &req); err == nil {
+		t.Error("expected an error for a non-string card number")
+	}
+
+	// Escapes are decoded like encoding/json does.
+	for data, want := range map[string]string{
+		`"4111\u0031111111111111"`: "41111111111111111",
+		`"41\/11\t\""`:             "41/11\t\"",
+		`"\ud83d\ude00 \ud83d"`:    "\U0001F600 \uFFFD",
+		`null`:                     "",
+	} {
+		var pan PAN
+		if err := json.Unmarshal([]byte(data), &pan); err != nil || pan.Raw() != want {
+			t.Errorf("%s: unexpected card number: %q, %v", data, pan.Raw(), err)
+		}
+	}
+	for _, data := range []string{`"4111\x"`, `"4111\u00"`, "\"4111\t\""} {
+		var pan PAN
+		if err := pan.UnmarshalJSON([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", data)
+		}
+	}
+
+	var pan PAN
+	if err := pan.UnmarshalText([]byte("5555555555554444")); err != nil || pan.Raw() != "5555555555554444" {
+		t.Errorf("unexpected card number: %s, %v", pan, err)
+	}
+}
+
+func TestPANZero(t *testing.T) {
+	pan := NewPAN("4111111111111111")
+	cp := pan
+	digits := pan.digits
+
+	pan.Zero()
+	if pan.Len() != 0 || pan.Raw() != "" {
+		t.Errorf("expected an empty card number, got %q", pan.Raw())
+	}
+	if !bytes.Equal(digits, make([]byte, 16)) {
+		t.Errorf("expected memory to be zeroed, got %q", digits)
+	}
+	if strings.Contains(cp.Raw(), "4111") {
+		t.Errorf("expected copies to be zeroed, got %q", cp.Raw())
+	}
+}
+
+func TestValidatePAN(t *testing.T) {
+	var v Validator
+	res, err := v.ValidatePAN(NewPAN("4111111111111111"), "08/2099")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if res.Issuer != issuer.Visa {
+		t.Errorf("unexpected issuer: %s", res.Issuer)
+	}
+}
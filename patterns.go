@@ -0,0 +1,192 @@
+package cardvalidate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSuspiciousNumber is returned for card numbers with synthetic digit patterns when
+// such numbers are rejected.
+var ErrSuspiciousNumber = errors.New("cardvalidate: suspicious card number")
+
+// Pattern finding codes.
+const (
+	CodeRepeatedDigits   = "repeated_digits"
+	CodeSequentialDigits = "sequential_digits"
+	CodeRepeatedBlock    = "repeated_block"
+)
+
+// PatternMode controls how validation treats card numbers with synthetic digit patterns.
+type PatternMode int
+
+const (
+	PatternsAllow  PatternMode = iota // Don't run pattern detectors.
+	PatternsWarn                      // Report matches as warnings.
+	PatternsReject                    // Report matches as errors and fail with ErrSuspiciousNumber.
+)
+
+// String implements fmt.Stringer
+func (m PatternMode) String() string {
+	switch m {
+	case PatternsAllow:
+		return "allow"
+	case PatternsWarn:
+		return "warn"
+	case PatternsReject:
+		return "reject"
+	default:
+		return fmt.Sprintf("PatternMode(%d)", m)
+	}
+}
+
+// ParsePatternMode parses a pattern mode from its name.
+func ParsePatternMode(s string) (PatternMode, error) {
+	switch s {
+	case "allow":
+		return PatternsAllow, nil
+	case "warn":
+		return PatternsWarn, nil
+	case "reject":
+		return PatternsReject, nil
+	default:
+		return 0, fmt.Errorf("cardvalidate: unknown pattern mode %q", s)
+	}
+}
+
+// Detector looks for a suspicious pattern in the account number, i.e. digits between the
+// 6-digit BIN and the check digit.
+type Detector struct {
+	Code    string
+	Score   float64 // Likelihood that a matching number is synthetic, from 0 to 1.
+	Message string
+	Match   func(account string) bool
+}
+
+// DefaultDetectors are run by validators that don't have their own detectors.
+var DefaultDetectors = []Detector{
+	{
+		Code:    CodeRepeatedDigits,
+		Score:   0.9,
+		Message: "Account number consists of a single repeated digit",
+		Match:   repeatedDigits,
+	},
+	{
+		Code:    CodeRepeatedBlock,
+		Score:   0.7,
+		Message: "Account number consists of a repeated block of digits",
+		Match:   repeatedBlock,
+	},
+	{
+		Code:    CodeSequentialDigits,
+		Score:   0.6,
+		Message: "Account number contains a long ascending or descending run",
+		Match:   sequentialDigits,
+	},
+}
+
+// minAccountLength is the shortest account number that pattern detectors are run on.
+const minAccountLength = 4
+
+// accountNumber returns cardNumber's digits between the BIN and the check digit.
+func accountNumber(cardNumber string) string {
+	if len(cardNumber) < 7 {
+		return ""
+	}
+	return cardNumber[6 : len(cardNumber)-1]
+}
+
+// repeatedDigits checks if account consists of a single repeated digit.
+func repeatedDigits(account string) bool {
+	return strings.Count(account, account[:1]) == len(account)
+}
+
+// repeatedBlock checks if account is made of a 2 to 4-digit block repeated at least twice.
+// A trailing partial block is allowed.
+func repeatedBlock(account string) bool {
+	for size := 2; size <= 4 && 2*size <= len(account); size++ {
+		block := account[:size]
+		if repeatedDigits(block) {
+			// Already covered by repeatedDigits.
+			continue
+		}
+
+		match := true
+		for i := size; i < len(account); i++ {
+			if account[i] != block[i%size] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// minSequenceLength is the shortest ascending or descending run sequentialDigits matches.
+const minSequenceLength = 6
+
+// sequentialDigits checks if account contains a run of consecutive ascending or descending
+// digits, e.g. 345678 or 987654.
+func sequentialDigits(account string) bool {
+	up, down := 1, 1
+	for i := 1; i < len(account); i++ {
+		d := int(account[i]) - int(account[i-1])
+		if d == 1 {
+			up++
+		} else {
+			up = 1
+		}
+		if d == -1 {
+			down++
+		} else {
+			down = 1
+		}
+		if up >= minSequenceLength || down >= minSequenceLength {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPatterns runs pattern detectors on cardNumber and records their matches in res.
+func (v *Validator) checkPatterns(cardNumber string, res *Result) error {
+	if v.PatternMode == PatternsAllow {
+		return nil
+	}
+
+	account := accountNumber(cardNumber)
+	if len(account) < minAccountLength {
+		return nil
+	}
+
+	detectors := v.Detectors
+	if detectors == nil {
+		detectors = DefaultDetectors
+	}
+
+	severity := SeverityWarning
+	if v.PatternMode == PatternsReject {
+		severity = SeverityError
+	}
+
+	matched := false
+	for _, d := range detectors {
+		if d.Match(account) {
+			matched = true
+			res.Findings = append(res.Findings, Finding{
+				Code:     d.Code,
+				Severity: severity,
+				Message:  d.Message,
+				Score:    d.Score,
+			})
+		}
+	}
+
+	if matched && v.PatternMode == PatternsReject {
+		return ErrSuspiciousNumber
+	}
+	return nil
+}
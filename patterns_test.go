@@ -0,0 +1,62 @@
+package cardvalidate
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDetectors(t *testing.T) {
+	tests := []struct {
+		account string
+		codes   []string
+	}{
+		{"111111111", []string{CodeRepeatedDigits}},
+		{"242424242", []string{CodeRepeatedBlock}},
+		{"123123123", []string{CodeRepeatedBlock}},
+		{"345678902", []string{CodeSequentialDigits}},
+		{"198765430", []string{CodeSequentialDigits}},
+		{"567890000", nil},
+		{"172947330", nil},
+		{"4929271", nil},
+	}
+
+	for _, tc := range tests {
+		var codes []string
+		for _, d := range DefaultDetectors {
+			if d.Match(tc.account) {
+				codes = append(codes, d.Code)
+			}
+		}
+		if !slices.Equal(codes, tc.codes) {
+			t.Errorf("detector mismatch (%s): want %v have %v", tc.account, tc.codes, codes)
+		}
+	}
+}
+
+func TestValidatorPatterns(t *testing.T) {
+	currentDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	v := &Validator{PatternMode: PatternsWarn}
+	res, err := v.validate(NewPAN("4111111111111111"), "08/2028", currentDate)
+	if err != nil {
+		t.Fatalf("unexpected validation error: %s", err)
+	}
+	if w := res.Warnings(); len(w) != 1 || w[0].Code != CodeRepeatedDigits || w[0].Score == 0 {
+		t.Errorf("unexpected warnings: %+v", w)
+	}
+
+	v = &Validator{PatternMode: PatternsReject}
+	res, err = v.validate(NewPAN("4242424242424242"), "08/2028", currentDate)
+	if !errors.Is(err, ErrSuspiciousNumber) {
+		t.Fatalf("unexpected error: want %s have %v", ErrSuspiciousNumber, err)
+	}
+	if len(res.Findings) != 1 || res.Findings[0].Severity != SeverityError {
+		t.Errorf("unexpected findings: %+v", res.Findings)
+	}
+
+	if _, err := v.validate(NewPAN("4539983514929271"), "08/2028", currentDate); err != nil {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+}
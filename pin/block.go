@@ -0,0 +1,216 @@
+package pin
+
+import (
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+// Format is an ISO 9564-1 PIN block format.
+type Format int
+
+const (
+	Format0 Format = 0 // PIN XOR PAN, padded with F.
+	Format1 Format = 1 // PIN with random padding, no PAN.
+	Format3 Format = 3 // PIN XOR PAN, padded with random A-F.
+	Format4 Format = 4 // 16-byte PIN and PAN fields enciphered with AES.
+)
+
+// String implements fmt.Stringer.
+func (f Format) String() string {
+	return fmt.Sprintf("ISO-%d", int(f))
+}
+
+// fillDigits are the hex digits each format pads the PIN with, picked at random if there are
+// several of them.
+var fillDigits = map[Format]string{
+	Format0: "f",
+	Format1: "0123456789abcdef",
+	Format3: "abcdef",
+	Format4: "a",
+}
+
+// Encode returns the clear 8-byte PIN block of pin in format 0, 1 or 3. Formats 0 and 3 combine
+// the PIN with the rightmost 12 digits of pan excluding the check digit, format 1 ignores pan.
+// Format 4 blocks only exist enciphered, see EncryptFormat4.
+func Encode(f Format, pin string, pan cardvalidate.PAN) ([]byte, error) {
+	if f != Format0 && f != Format1 && f != Format3 {
+		return nil, fmt.Errorf("%w: unsupported format %s", ErrInvalidBlock, f)
+	}
+	if err := checkPIN(pin); err != nil {
+		return nil, err
+	}
+
+	// Control field, PIN length, PIN and fill digits.
+	field := make([]byte, 16)
+	field[0], field[1] = byte('0'+f), hex.EncodeToString([]byte{byte(len(pin))})[1]
+	copy(field[2:], pin)
+	if err := fill(field[2+len(pin):], fillDigits[f]); err != nil {
+		return nil, err
+	}
+	block, err := hex.DecodeString(string(field))
+	if err != nil {
+		return nil, err
+	}
+
+	if f != Format1 {
+		panField, err := panField(pan)
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(block, block, panField)
+	}
+	return block, nil
+}
+
+// Decode returns the PIN from clear 8-byte PIN block in format 0, 1 or 3 made with pan, which
+// is ignored for format 1.
+func Decode(f Format, block []byte, pan cardvalidate.PAN) (string, error) {
+	if f != Format0 && f != Format1 && f != Format3 {
+		return "", fmt.Errorf("%w: unsupported format %s", ErrInvalidBlock, f)
+	}
+	if len(block) != 8 {
+		return "", fmt.Errorf("%w: expected 8 bytes, got %d", ErrInvalidBlock, len(block))
+	}
+
+	plain := make([]byte, 8)
+	copy(plain, block)
+	if f != Format1 {
+		panField, err := panField(pan)
+		if err != nil {
+			return "", err
+		}
+		subtle.XORBytes(plain, plain, panField)
+	}
+	return parsePINField(hex.EncodeToString(plain), f)
+}
+
+// panField returns the format 0 and 3 PAN field: four zeros followed by the rightmost 12 digits
+// of pan excluding the check digit.
+func panField(pan cardvalidate.PAN) ([]byte, error) {
+	digits, err := panDigits(pan)
+	if err != nil {
+		return nil, err
+	}
+	return hex.DecodeString("0000" + digits[len(digits)-13:len(digits)-1])
+}
+
+// EncryptFormat4 returns the format 4 PIN block of pin and pan enciphered with AES key.
+func EncryptFormat4(key []byte, pin string, pan cardvalidate.PAN) ([]byte, error) {
+	if err := checkPIN(pin); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	panField, err := format4PANField(pan)
+	if err != nil {
+		return nil, err
+	}
+
+	// Control field, PIN length, PIN, A fill up to the 16th digit, then 16 random digits.
+	field := make([]byte, 32)
+	field[0], field[1] = '4', hex.EncodeToString([]byte{byte(len(pin))})[1]
+	copy(field[2:], pin)
+	if err := fill(field[2+len(pin):16], fillDigits[Format4]); err != nil {
+		return nil, err
+	}
+	if err := fill(field[16:], "0123456789abcdef"); err != nil {
+		return nil, err
+	}
+	out, err := hex.DecodeString(string(field))
+	if err != nil {
+		return nil, err
+	}
+
+	block.Encrypt(out, out)
+	subtle.XORBytes(out, out, panField)
+	block.Encrypt(out, out)
+	return out, nil
+}
+
+// DecryptFormat4 returns the PIN from a format 4 PIN block made with pan and AES key.
+func DecryptFormat4(key, pinBlock []byte, pan cardvalidate.PAN) (string, error) {
+	if len(pinBlock) != aes.BlockSize {
+		return "", fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidBlock, aes.BlockSize, len(pinBlock))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	panField, err := format4PANField(pan)
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, aes.BlockSize)
+	block.Decrypt(out, pinBlock)
+	subtle.XORBytes(out, out, panField)
+	block.Decrypt(out, out)
+	// Only the first 16 digits are checked, the rest is random.
+	return parsePINField(hex.EncodeToString(out)[:16], Format4)
+}
+
+// format4PANField returns the format 4 PAN field: the PAN length minus 12, the PAN and zero
+// padding to 32 digits.
+func format4PANField(pan cardvalidate.PAN) ([]byte, error) {
+	digits, err := panDigits(pan)
+	if err != nil {
+		return nil, err
+	}
+	field := fmt.Sprintf("%d%s", len(digits)-12, digits)
+	return hex.DecodeString(field + strings.Repeat("0", 32-len(field)))
+}
+
+// parsePINField parses a lower-case hex PIN field of format f.
+func parsePINField(field string, f Format) (string, error) {
+	if field[0] != byte('0'+f) {
+		return "", fmt.Errorf("%w: control field %c", ErrInvalidBlock, field[0])
+	}
+	n := strings.IndexByte("0123456789abcdef", field[1])
+	if n < MinLength || n > MaxLength {
+		return "", fmt.Errorf("%w: PIN length %d", ErrInvalidBlock, n)
+	}
+	pin, padding := field[2:2+n], field[2+n:]
+	if !isDigits(pin) {
+		return "", fmt.Errorf("%w: non-digit PIN", ErrInvalidBlock)
+	}
+	for _, c := range []byte(padding) {
+		if strings.IndexByte(fillDigits[f], c) < 0 {
+			return "", fmt.Errorf("%w: invalid fill digit %c", ErrInvalidBlock, c)
+		}
+	}
+	return pin, nil
+}
+
+// fill fills b with digits picked uniformly at random from digits.
+func fill(b []byte, digits string) error {
+	if len(digits) == 1 {
+		for i := range b {
+			b[i] = digits[0]
+		}
+		return nil
+	}
+
+	buf := make([]byte, len(b))
+	for i := 0; i < len(b); {
+		if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+			return err
+		}
+		for _, r := range buf {
+			// Reject bytes past the largest multiple of len(digits) to avoid bias.
+			if i < len(b) && int(r) < 256-256%len(digits) {
+				b[i] = digits[int(r)%len(digits)]
+				i++
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package pin
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+func TestEncodeFormat0(t *testing.T) {
+	tests := []struct {
+		pin, pan string
+		want     string
+	}{
+		{"1234", "4111111111111111", "041225eeeeeeeeee"},
+		{"12345", "5555555555554444", "0512610aaaaaabbb"},
+	}
+	for _, tc := range tests {
+		pan := cardvalidate.NewPAN(tc.pan)
+		block, err := Encode(Format0, tc.pin, pan)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if have := hex.EncodeToString(block); have != tc.want {
+			t.Errorf("%s/%s: want %s have %s", tc.pin, tc.pan, tc.want, have)
+		}
+		if pin, err := Decode(Format0, block, pan); err != nil || pin != tc.pin {
+			t.Errorf("%s: Decode() = %q, %v", tc.want, pin, err)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	pan := cardvalidate.NewPAN("4111111111111111")
+	tests := []struct {
+		format Format
+		block  string
+		want   string
+	}{
+		{Format0, "041225eeeeeeeeee", "1234"},
+		{Format1, "1412340123456789", "1234"},
+		{Format1, "1c123456789012ab", "123456789012"},
+		{Format3, "341225badcfebadc", "1234"},
+	}
+	for _, tc := range tests {
+		block, _ := hex.DecodeString(tc.block)
+		have, err := Decode(tc.format, block, pan)
+		if err != nil {
+			t.Errorf("%s %s: unexpected error: %s", tc.format, tc.block, err)
+		} else if have != tc.want {
+			t.Errorf("%s %s: want %s have %s", tc.format, tc.block, tc.want, have)
+		}
+	}
+}
+
+func TestEncodeRandomFill(t *testing.T) {
+	pan := cardvalidate.NewPAN("4111111111111111")
+	for _, f := range []Format{Format1, Format3} {
+		first, err := Encode(f, "123456", pan)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", f, err)
+		}
+		second, _ := Encode(f, "123456", pan)
+		if string(first) == string(second) {
+			t.Errorf("%s: PIN blocks of the same PIN are equal", f)
+		}
+		for _, block := range [][]byte{first, second} {
+			if pin, err := Decode(f, block, pan); err != nil || pin != "123456" {
+				t.Errorf("%s: Decode() = %q, %v", f, pin, err)
+			}
+		}
+	}
+
+	// Format 1 doesn't depend on the card number.
+	block, _ := Encode(Format1, "1234", cardvalidate.PAN{})
+	if pin, err := Decode(Format1, block, cardvalidate.PAN{}); err != nil || pin != "1234" {
+		t.Errorf("format 1 without PAN: Decode() = %q, %v", pin, err)
+	}
+}
+
+func TestFormat4(t *testing.T) {
+	key, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+	pan := cardvalidate.NewPAN("4111111111111111")
+
+	// PAN fields of ISO 9564-1:2017 format 4: the PAN length minus 12, the PAN and zero padding.
+	for number, want := range map[string]string{
+		"4111111111111111":    "44111111111111111000000000000000",
+		"6212345678900000003": "76212345678900000003000000000000",
+	} {
+		field, err := format4PANField(cardvalidate.NewPAN(number))
+		if have := hex.EncodeToString(field); err != nil || have != want {
+			t.Errorf("%s: unexpected PAN field %s, %v", number, have, err)
+		}
+	}
+
+	// PIN 1234 with random fill 0123456789abcdef, i.e. PIN field 441234aaaaaaaaaa0123456789abcdef,
+	// enciphered as AES(key, AES(key, PIN field) XOR PAN field). Reproducible with openssl:
+	//
+	//	echo 441234aaaaaaaaaa0123456789abcdef | xxd -r -p | openssl enc -aes-128-ecb -nopad -K $key | xxd -p
+	//	  => ce4cf13804091b324930e585aaaac994, XOR PAN field => 8a5de02915180a235930e585aaaac994
+	//	echo 8a5de02915180a235930e585aaaac994 | xxd -r -p | openssl enc -aes-128-ecb -nopad -K $key | xxd -p
+	//	  => f4df86a4ae200e19e0f01167bd3defd9
+	block, _ := hex.DecodeString("f4df86a4ae200e19e0f01167bd3defd9")
+	if pin, err := DecryptFormat4(key, block, pan); err != nil || pin != "1234" {
+		t.Errorf("DecryptFormat4() = %q, %v", pin, err)
+	}
+	if _, err := DecryptFormat4(key, block, cardvalidate.NewPAN("5555555555554444")); !errors.Is(err, ErrInvalidBlock) {
+		t.Errorf("wrong PAN: unexpected error: %v", err)
+	}
+
+	for _, size := range []int{16, 24, 32} {
+		key := []byte(strings.Repeat("k", size))
+		first, err := EncryptFormat4(key, "987654321", pan)
+		if err != nil {
+			t.Fatalf("AES-%d: unexpected error: %s", 8*size, err)
+		}
+		second, _ := EncryptFormat4(key, "987654321", pan)
+		if string(first) == string(second) {
+			t.Errorf("AES-%d: PIN blocks of the same PIN are equal", 8*size)
+		}
+		if pin, err := DecryptFormat4(key, first, pan); err != nil || pin != "987654321" {
+			t.Errorf("AES-%d: DecryptFormat4() = %q, %v", 8*size, pin, err)
+		}
+	}
+
+	if _, err := EncryptFormat4(key[:10], "1234", pan); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("short key: unexpected error: %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	pan := cardvalidate.NewPAN("4111111111111111")
+	key := make([]byte, 16)
+
+	for _, pin := range []string{"123", "1234567890123", "12a4", ""} {
+		if _, err := Encode(Format0, pin, pan); !errors.Is(err, ErrInvalidPIN) {
+			t.Errorf("Encode(%q): unexpected error: %v", pin, err)
+		}
+		if _, err := EncryptFormat4(key, pin, pan); !errors.Is(err, ErrInvalidPIN) {
+			t.Errorf("EncryptFormat4(%q): unexpected error: %v", pin, err)
+		}
+	}
+
+	for _, number := range []string{"4111111111111112", "411111111111", "41111111111111111111", "4111x11111111111"} {
+		bad := cardvalidate.NewPAN(number)
+		if _, err := Encode(Format3, "1234", bad); !errors.Is(err, ErrInvalidPAN) {
+			t.Errorf("Encode(%s): unexpected error: %v", number, err)
+		}
+		if _, err := EncryptFormat4(key, "1234", bad); !errors.Is(err, ErrInvalidPAN) {
+			t.Errorf("EncryptFormat4(%s): unexpected error: %v", number, err)
+		}
+	}
+
+	if _, err := Encode(Format(2), "1234", pan); !errors.Is(err, ErrInvalidBlock) {
+		t.Errorf("format 2: unexpected error: %v", err)
+	}
+	if _, err := Encode(Format4, "1234", pan); !errors.Is(err, ErrInvalidBlock) {
+		t.Errorf("clear format 4: unexpected error: %v", err)
+	}
+
+	blocks := []struct {
+		format Format
+		block  string
+	}{
+		{Format3, "041225eeeeeeeeee"}, // Format 0 block.
+		{Format0, "041225eeeeeeee"},   // Too short.
+		{Format0, "0412250eeeeeeeee"}, // Bad fill.
+		{Format0, "0312250eeeeeeeee"}, // PIN too short.
+		{Format1, "14123a0123456789"}, // Non-digit PIN.
+	}
+	for _, tc := range blocks {
+		block, _ := hex.DecodeString(tc.block)
+		if _, err := Decode(tc.format, block, pan); !errors.Is(err, ErrInvalidBlock) {
+			t.Errorf("%s %s: unexpected error: %v", tc.format, tc.block, err)
+		}
+	}
+}
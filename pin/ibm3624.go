@@ -0,0 +1,83 @@
+package pin
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+// DefaultDecimalizationTable maps hex digits 0-F to 0123456789012345.
+const DefaultDecimalizationTable = "0123456789012345"
+
+// IBM3624 derives natural PINs and PIN offsets with the IBM 3624 method. The validation data is
+// the rightmost 12 digits of the card number excluding the check digit, padded with F.
+type IBM3624 struct {
+	block cipher.Block
+	table string
+}
+
+// NewIBM3624 returns an IBM 3624 PIN generator with hex-encoded single, double or triple-length
+// PIN generation key pvk and a decimalization table of 16 digits.
+func NewIBM3624(pvk, decimalizationTable string) (*IBM3624, error) {
+	if len(decimalizationTable) != 16 || !isDigits(decimalizationTable) {
+		return nil, fmt.Errorf("pin: decimalization table must be 16 digits")
+	}
+	block, err := newTripleDES(pvk)
+	if err != nil {
+		return nil, err
+	}
+	return &IBM3624{block: block, table: decimalizationTable}, nil
+}
+
+// NaturalPIN returns the natural PIN of pan with length digits.
+func (m *IBM3624) NaturalPIN(pan cardvalidate.PAN, length int) (string, error) {
+	if length < MinLength || length > MaxLength {
+		return "", fmt.Errorf("%w: expected %d to %d digits", ErrInvalidPIN, MinLength, MaxLength)
+	}
+	digits, err := panDigits(pan)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := hex.DecodeString(digits[len(digits)-13:len(digits)-1] + "ffff")
+	if err != nil {
+		return "", err
+	}
+	m.block.Encrypt(data, data)
+
+	natural := []byte(hex.EncodeToString(data)[:length])
+	for i, c := range natural {
+		natural[i] = m.table[strings.IndexByte("0123456789abcdef", c)]
+	}
+	return string(natural), nil
+}
+
+// Offset returns the offset that turns the natural PIN of pan into pin, digit by digit mod 10.
+func (m *IBM3624) Offset(pan cardvalidate.PAN, pin string) (string, error) {
+	if err := checkPIN(pin); err != nil {
+		return "", err
+	}
+	natural, err := m.NaturalPIN(pan, len(pin))
+	if err != nil {
+		return "", err
+	}
+
+	offset := make([]byte, len(pin))
+	for i := range offset {
+		offset[i] = byte('0' + (int(pin[i])-int(natural[i])+10)%10)
+	}
+	return string(offset), nil
+}
+
+// Verify checks that offset turns the natural PIN of pan into pin.
+func (m *IBM3624) Verify(pan cardvalidate.PAN, pin, offset string) (bool, error) {
+	want, err := m.Offset(pan, pin)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(want), []byte(offset)) == 1, nil
+}
@@ -0,0 +1,52 @@
+// Package pin builds and parses ISO 9564 PIN blocks and generates PIN verification values with
+// the Visa PVV and IBM 3624 methods, for terminal simulators and issuer host emulators.
+package pin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/internal/luhn"
+)
+
+var (
+	ErrInvalidPIN   = errors.New("pin: invalid PIN")
+	ErrInvalidPAN   = errors.New("pin: invalid card number")
+	ErrInvalidKey   = errors.New("pin: invalid key")
+	ErrInvalidBlock = errors.New("pin: invalid PIN block")
+)
+
+// PIN length limits of ISO 9564.
+const (
+	MinLength = 4
+	MaxLength = 12
+)
+
+// checkPIN checks that pin is made of MinLength to MaxLength digits.
+func checkPIN(pin string) error {
+	if len(pin) < MinLength || len(pin) > MaxLength || !isDigits(pin) {
+		return fmt.Errorf("%w: expected %d to %d digits", ErrInvalidPIN, MinLength, MaxLength)
+	}
+	return nil
+}
+
+// panDigits returns the digits of pan after checking that it's a card number of 13 to 19 digits
+// that passes Luhn's check.
+func panDigits(pan cardvalidate.PAN) (string, error) {
+	digits := pan.Raw()
+	if len(digits) < 13 || len(digits) > 19 || !isDigits(digits) || !luhn.Valid(digits) {
+		return "", ErrInvalidPAN
+	}
+	return digits, nil
+}
+
+// isDigits checks that s is made of decimal digits only.
+func isDigits(s string) bool {
+	for _, c := range []byte(s) {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
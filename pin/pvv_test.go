@@ -0,0 +1,119 @@
+package pin
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+func TestVisaPVV(t *testing.T) {
+	v, err := NewVisaPVV("0123456789ABCDEFFEDCBA9876543210")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		pan  string
+		pvki int
+		pin  string
+		want string
+	}{
+		{"4111111111111111", 1, "1234", "9464"},
+		{"5555555555554444", 2, "987654", "4394"}, // Only the first 4 PIN digits count.
+		{"5555555555554444", 2, "9876", "4394"},
+	}
+	for _, tc := range tests {
+		pan := cardvalidate.NewPAN(tc.pan)
+		have, err := v.Generate(pan, tc.pvki, tc.pin)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.pan, err)
+			continue
+		}
+		if have != tc.want {
+			t.Errorf("%s/%d/%s: want %s have %s", tc.pan, tc.pvki, tc.pin, tc.want, have)
+		}
+		if ok, err := v.Verify(pan, tc.pvki, tc.pin, tc.want); err != nil || !ok {
+			t.Errorf("%s: Verify() = %t, %v", tc.pan, ok, err)
+		}
+		if ok, _ := v.Verify(pan, tc.pvki+1, tc.pin, tc.want); ok {
+			t.Errorf("%s: PVV verified with another PVKI", tc.pan)
+		}
+	}
+
+	pan := cardvalidate.NewPAN("4111111111111111")
+	if _, err := v.Generate(pan, 10, "1234"); err == nil {
+		t.Error("expected an error for PVKI 10")
+	}
+	if _, err := v.Generate(pan, 1, "123"); !errors.Is(err, ErrInvalidPIN) {
+		t.Errorf("short PIN: unexpected error: %v", err)
+	}
+	if _, err := v.Generate(cardvalidate.NewPAN("4111111111111112"), 1, "1234"); !errors.Is(err, ErrInvalidPAN) {
+		t.Errorf("invalid PAN: unexpected error: %v", err)
+	}
+	for _, pvk := range []string{"0123456789ABCDEF", "0123456789ABCDEFFEDCBA987654321G"} {
+		if _, err := NewVisaPVV(pvk); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("NewVisaPVV(%q): unexpected error: %v", pvk, err)
+		}
+	}
+}
+
+func TestIBM3624(t *testing.T) {
+	tests := []struct {
+		pvk     string
+		pan     string
+		pin     string
+		natural string
+		offset  string
+	}{
+		{"0123456789ABCDEF", "4111111111111111", "1234", "3554", "8780"},
+		{"0123456789ABCDEFFEDCBA9876543210", "5555555555554444", "987654", "511435", "476229"},
+	}
+	for _, tc := range tests {
+		m, err := NewIBM3624(tc.pvk, DefaultDecimalizationTable)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		pan := cardvalidate.NewPAN(tc.pan)
+
+		natural, err := m.NaturalPIN(pan, len(tc.pin))
+		if err != nil || natural != tc.natural {
+			t.Errorf("%s: NaturalPIN() = %q, %v, want %s", tc.pan, natural, err, tc.natural)
+		}
+		offset, err := m.Offset(pan, tc.pin)
+		if err != nil || offset != tc.offset {
+			t.Errorf("%s: Offset() = %q, %v, want %s", tc.pan, offset, err, tc.offset)
+		}
+		if ok, err := m.Verify(pan, tc.pin, tc.offset); err != nil || !ok {
+			t.Errorf("%s: Verify() = %t, %v", tc.pan, ok, err)
+		}
+		if ok, _ := m.Verify(pan, tc.pin, tc.natural); ok {
+			t.Errorf("%s: wrong offset verified", tc.pan)
+		}
+
+		// The natural PIN is its own PIN with a zero offset.
+		if offset, _ := m.Offset(pan, natural); offset != "000000"[:len(natural)] {
+			t.Errorf("%s: offset of the natural PIN: %s", tc.pan, offset)
+		}
+	}
+
+	// The decimalization table maps each hex digit of the enciphered validation data.
+	m, _ := NewIBM3624("0123456789ABCDEF", "9876543210987654")
+	if natural, _ := m.NaturalPIN(cardvalidate.NewPAN("4111111111111111"), 4); natural != "6445" {
+		t.Errorf("custom table: want 6445 have %s", natural)
+	}
+
+	if _, err := NewIBM3624("0123456789ABCDEF", "01234567890123"); err == nil {
+		t.Error("expected an error for a short decimalization table")
+	}
+	if _, err := NewIBM3624("0123", DefaultDecimalizationTable); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("short key: unexpected error: %v", err)
+	}
+	m, _ = NewIBM3624("0123456789ABCDEF", DefaultDecimalizationTable)
+	if _, err := m.NaturalPIN(cardvalidate.NewPAN("4111111111111111"), 13); !errors.Is(err, ErrInvalidPIN) {
+		t.Errorf("long PIN: unexpected error: %v", err)
+	}
+	if _, err := m.Offset(cardvalidate.NewPAN("411111111111"), "1234"); !errors.Is(err, ErrInvalidPAN) {
+		t.Errorf("short PAN: unexpected error: %v", err)
+	}
+}
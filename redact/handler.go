@@ -0,0 +1,89 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Handler is a slog.Handler middleware that masks card numbers in log messages and attribute
+// values before passing records to the next handler.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler returns a Handler that passes redacted records to next.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled implements slog.Handler.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr masks card numbers in a's value. Values that don't hold card numbers keep their
+// kind, values that do are replaced with redacted strings.
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(String(v.String()))
+	case slog.KindGroup:
+		attrs := v.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, ga := range attrs {
+			redacted[i] = redactAttr(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindInt64, slog.KindUint64:
+		if s := v.String(); String(s) != s {
+			a.Value = slog.StringValue(String(s))
+		} else {
+			a.Value = v
+		}
+	case slog.KindAny:
+		// Format arbitrary values the way handlers would, e.g. errors through Error().
+		var s string
+		switch x := v.Any().(type) {
+		case error:
+			s = x.Error()
+		case fmt.Stringer:
+			s = x.String()
+		default:
+			s = fmt.Sprintf("%+v", x)
+		}
+		if r := String(s); r != s {
+			a.Value = slog.StringValue(r)
+		} else {
+			a.Value = v
+		}
+	default:
+		a.Value = v
+	}
+	return a
+}
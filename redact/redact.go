@@ -0,0 +1,136 @@
+// Package redact masks card numbers in logs and other text before it leaves the process.
+package redact
+
+import (
+	"bytes"
+
+	"github.com/waterfountain1996/cardvalidate/internal/luhn"
+)
+
+// Card number length limits.
+const (
+	minDigits = 13
+	maxDigits = 19
+)
+
+// Mask is the character that replaces hidden digits.
+const Mask = '*'
+
+// String returns s with all card numbers masked, see Bytes.
+func String(s string) string {
+	b := []byte(s)
+	if !redact(b) {
+		return s
+	}
+	return string(b)
+}
+
+// Bytes masks card numbers in b in place and returns it. A card number is a Luhn-valid run of
+// 13 to 19 digits that may be split into groups by single spaces or dashes. All but the first
+// 6 and the last 4 digits are masked, separators are kept as is.
+func Bytes(b []byte) []byte {
+	redact(b)
+	return b
+}
+
+// redact masks card numbers in b in place and reports if anything was masked.
+func redact(b []byte) bool {
+	masked := false
+	for i := 0; i < len(b); {
+		if !isDigit(b[i]) {
+			i++
+			continue
+		}
+		groups := digitGroups(b, i)
+		if maskGroups(b, groups) {
+			masked = true
+		}
+		i = groups[len(groups)-1][1]
+	}
+	return masked
+}
+
+// digitGroups returns bounds of digit groups that start at i and are joined by single spaces
+// or dashes.
+func digitGroups(b []byte, i int) [][2]int {
+	var groups [][2]int
+	start := i
+	for j := i; ; j++ {
+		if j < len(b) && isDigit(b[j]) {
+			continue
+		}
+		groups = append(groups, [2]int{start, j})
+		if j+1 < len(b) && (b[j] == ' ' || b[j] == '-') && isDigit(b[j+1]) {
+			start = j + 1
+			continue
+		}
+		return groups
+	}
+}
+
+// maskGroups masks card numbers made of consecutive groups. A number never starts or ends
+// inside a group, so long identifiers made of contiguous digits are left alone.
+func maskGroups(b []byte, groups [][2]int) bool {
+	masked := false
+	for i := 0; i < len(groups); {
+		// Prefer the longest card number starting at group i.
+		end := -1
+		digits := make([]byte, 0, maxDigits)
+		for j := i; j < len(groups); j++ {
+			digits = append(digits, b[groups[j][0]:groups[j][1]]...)
+			if len(digits) > maxDigits {
+				break
+			}
+			if len(digits) >= minDigits && luhn.Valid(string(digits)) {
+				end = j
+			}
+		}
+		if end < 0 {
+			i++
+			continue
+		}
+
+		maskDigits(b, groups[i:end+1])
+		masked = true
+		i = end + 1
+	}
+	return masked
+}
+
+// maskDigits masks all but the first 6 and the last 4 digits in groups.
+func maskDigits(b []byte, groups [][2]int) {
+	n := 0
+	for _, g := range groups {
+		n += g[1] - g[0]
+	}
+
+	k := 0
+	for _, g := range groups {
+		for p := g[0]; p < g[1]; p++ {
+			if k >= 6 && k < n-4 {
+				b[p] = Mask
+			}
+			k++
+		}
+	}
+}
+
+// pendingStart returns the index where a trailing run of digits and separators begins in b,
+// i.e. the part of b that may be the beginning of a card number continued in the next write.
+// It returns len(b) if there is no such run.
+func pendingStart(b []byte) int {
+	i := len(b)
+	for i > 0 && (isDigit(b[i-1]) || b[i-1] == ' ' || b[i-1] == '-') {
+		i--
+	}
+	// Leading separators can't start a card number.
+	if j := bytes.IndexFunc(b[i:], func(r rune) bool { return '0' <= r && r <= '9' }); j >= 0 {
+		return i + j
+	}
+	return len(b)
+}
+
+// isDigit checks if c is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,103 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"card 4111111111111111 declined", "card 411111******1111 declined"},
+		{"4111 1111 1111 1111", "4111 11** **** 1111"},
+		{"amex 3782-822463-10005.", "amex 3782-82****-*0005."},
+		{"4111111111111111 5555555555554444", "411111******1111 555555******4444"},
+		{"pan=4111111111111111&exp=12/30", "pan=411111******1111&exp=12/30"},
+		{"not luhn 4111111111111112", "not luhn 4111111111111112"},
+		{"too short 4111111111", "too short 4111111111"},
+		{"long id 41111111111111111111", "long id 41111111111111111111"},
+		{"order 12 4111111111111111", "order 12 411111******1111"},
+		{"", ""},
+	}
+
+	for _, c := range cases {
+		if got := String(c.in); got != c.want {
+			t.Errorf("String(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestWriterSplitWrites(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	for _, p := range []string{"card 4111 11", "11 1111", " 1111 ok\n", "tail 5555555555"} {
+		if _, err := w.Write([]byte(p)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if got := buf.String(); got != "card 4111 11** **** 1111 ok\ntail " {
+		t.Errorf("unexpected output before Flush: %q", got)
+	}
+
+	if _, err := w.Write([]byte("554444\n")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := buf.String(); got != "card 4111 11** **** 1111 ok\ntail 555555******4444\n" {
+		t.Errorf("unexpected output: %q", got)
+	}
+}
+
+func TestWriterPendingLimit(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	digits := strings.Repeat("1", maxPending+1)
+	if _, err := w.Write([]byte(digits)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if buf.Len() != len(digits) {
+		t.Errorf("expected long digit runs to be written out, got %d bytes", buf.Len())
+	}
+}
+
+type stringer struct{ s string }
+
+func (s stringer) String() string { return s.s }
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+
+	logger.With("pan", "4111111111111111").WithGroup("req").Info(
+		"charge 5555555555554444 failed",
+		"err", fmt.Errorf("wrapped: %w", errors.New("card 4012888888881881")),
+		"num", int64(4111111111111111),
+		"obj", stringer{"6011111111111117"},
+		"user", slog.GroupValue(slog.String("card", "378282246310005"), slog.Int("age", 42)),
+		"count", 3,
+	)
+
+	want := `level=INFO msg="charge 555555******4444 failed" pan=411111******1111 ` +
+		`req.err="wrapped: card 401288******1881" req.num=411111******1111 ` +
+		`req.obj=601111******1117 req.user.card=378282*****0005 req.user.age=42 req.count=3` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected output:\n got %s\nwant %s", got, want)
+	}
+}
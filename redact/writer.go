@@ -0,0 +1,58 @@
+package redact
+
+import (
+	"io"
+	"sync"
+)
+
+// maxPending is the most bytes a Writer holds back waiting for the rest of a card number.
+const maxPending = 4096
+
+// Writer masks card numbers in data written to an underlying writer. A trailing run of digits
+// is held back until the next write or Flush, so card numbers split between writes are
+// masked as well. It's safe for concurrent use.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	pending []byte
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	n := pendingStart(w.pending)
+	if len(w.pending)-n > maxPending {
+		n = len(w.pending)
+	}
+	if n == 0 {
+		return len(p), nil
+	}
+
+	if _, err := w.w.Write(Bytes(w.pending[:n])); err != nil {
+		w.pending = w.pending[:0]
+		return 0, err
+	}
+	w.pending = append(w.pending[:0], w.pending[n:]...)
+	return len(p), nil
+}
+
+// Flush writes out data held back by w.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := w.w.Write(Bytes(w.pending))
+	w.pending = w.pending[:0]
+	return err
+}
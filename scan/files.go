@@ -0,0 +1,285 @@
+package scan
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrArchiveTooLarge is returned for nested zip archives that don't fit in memory limits.
+var ErrArchiveTooLarge = errors.New("scan: nested archive is too large")
+
+// maxNestedZip is the largest zip archive inside another archive that is scanned.
+// Unlike tar and gzip, zip archives need random access and are read into memory.
+const maxNestedZip = 64 << 20
+
+// archiveSep separates an archive path from the path of a file inside of it.
+const archiveSep = "!"
+
+// FileMatch is a card number found in a file.
+type FileMatch struct {
+	// Path of the file. Files inside archives are reported as archive path and member path
+	// joined with '!', e.g. logs.tar.gz!app/app.log.
+	Path string
+
+	File   string // File on disk, the outermost archive for files inside archives.
+	Member string // Path inside File, empty for plain files. Nested members are joined with '!'.
+
+	Match
+}
+
+// Options configure which files are scanned and how.
+type Options struct {
+	// Include holds glob patterns of files to scan, all files if empty. Patterns are
+	// matched against both the file's base name and its slash-separated path.
+	Include []string
+
+	// Exclude holds glob patterns of files and directories to skip.
+	Exclude []string
+
+	// Workers is the number of files scanned in parallel, the number of CPUs if zero.
+	Workers int
+
+	// MinScore is the lowest score a match must have to be reported.
+	MinScore float64
+}
+
+// Files walks paths, which may be files or directories, and scans plain files as well as gzip,
+// zip and tar archives for card numbers. Matches are sorted by path and offset.
+// Files that can't be read don't stop the walk, their errors are joined and returned along
+// with matches found in other files.
+func Files(paths []string, opts Options) ([]FileMatch, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var (
+		mu      sync.Mutex
+		matches []FileMatch
+		errs    []error
+		wg      sync.WaitGroup
+	)
+	files := make(chan string)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range files {
+				found, err := scanFile(name, opts.MinScore)
+				mu.Lock()
+				matches = append(matches, found...)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var walkErrs []error
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				walkErrs = append(walkErrs, err)
+				return nil
+			}
+			if name != root && matchAny(opts.Exclude, name) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			if len(opts.Include) > 0 && !matchAny(opts.Include, name) {
+				return nil
+			}
+			files <- name
+			return nil
+		})
+		if err != nil {
+			walkErrs = append(walkErrs, err)
+		}
+	}
+	close(files)
+	wg.Wait()
+
+	slices.SortFunc(matches, func(a, b FileMatch) int {
+		return cmp.Or(cmp.Compare(a.Path, b.Path), cmp.Compare(a.Offset, b.Offset))
+	})
+	return matches, errors.Join(append(walkErrs, errs...)...)
+}
+
+// matchAny checks if name matches any of the glob patterns.
+func matchAny(patterns []string, name string) bool {
+	name = filepath.ToSlash(name)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, path.Base(name)); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// scanFile scans the file name, which may be an archive.
+func scanFile(name string, minScore float64) ([]FileMatch, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var matches []FileMatch
+	if isZip(name) {
+		// Zip archives on disk are read in place instead of being loaded into memory.
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		matches, err = scanZip(name, "", f, info.Size(), minScore)
+	} else {
+		matches, err = scanReader(name, "", f, minScore)
+	}
+	for i := range matches {
+		matches[i].File = name
+	}
+	return matches, err
+}
+
+// scanReader scans r which holds the contents of the file name, the archive member member
+// if it's inside an archive.
+func scanReader(name, member string, r io.Reader, minScore float64) ([]FileMatch, error) {
+	lower := strings.ToLower(name)
+	switch {
+	case isZip(name):
+		b, err := io.ReadAll(io.LimitReader(r, maxNestedZip+1))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if len(b) > maxNestedZip {
+			return nil, fmt.Errorf("%s: %w", name, ErrArchiveTooLarge)
+		}
+		return scanZip(name, member, bytes.NewReader(b), int64(len(b)), minScore)
+	case strings.HasSuffix(lower, ".tar"):
+		return scanTar(name, member, r, minScore)
+	case strings.HasSuffix(lower, ".tgz"), strings.HasSuffix(lower, ".gz"):
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		defer zr.Close()
+
+		// Name the decompressed stream after the archive so that nested archive types are
+		// recognized, e.g. logs.tgz holds logs.tar.
+		inner := name[:len(name)-len(path.Ext(name))]
+		if strings.HasSuffix(lower, ".tgz") {
+			inner += ".tar"
+		}
+		matches, err := scanReader(inner, member, zr, minScore)
+		for i := range matches {
+			matches[i].Path = name + strings.TrimPrefix(matches[i].Path, inner)
+		}
+		return matches, err
+	}
+
+	var matches []FileMatch
+	s := NewScanner(r)
+	s.MinScore = minScore
+	for s.Scan() {
+		matches = append(matches, FileMatch{Path: name, Member: member, Match: s.Match()})
+	}
+	if err := s.Err(); err != nil {
+		return matches, fmt.Errorf("%s: %w", name, err)
+	}
+	return matches, nil
+}
+
+// scanTar scans regular files in the tar archive name, which is the archive member member if
+// it's nested in another archive.
+func scanTar(name, member string, r io.Reader, minScore float64) ([]FileMatch, error) {
+	var matches []FileMatch
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return matches, nil
+		}
+		if err != nil {
+			return matches, fmt.Errorf("%s: %w", name, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		found, err := scanReader(name+archiveSep+hdr.Name, memberPath(member, hdr.Name), tr, minScore)
+		matches = append(matches, found...)
+		if err != nil {
+			return matches, err
+		}
+	}
+}
+
+// scanZip scans regular files in the zip archive name, which is the archive member member if
+// it's nested in another archive.
+func scanZip(name, member string, r io.ReaderAt, size int64, minScore float64) ([]FileMatch, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var (
+		matches []FileMatch
+		errs    []error
+	)
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+
+		fullName := name + archiveSep + f.Name
+		rc, err := f.Open()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fullName, err))
+			continue
+		}
+		found, err := scanReader(fullName, memberPath(member, f.Name), rc, minScore)
+		rc.Close()
+		matches = append(matches, found...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return matches, errors.Join(errs...)
+}
+
+// memberPath returns the path of the file name inside the archive member, or name itself if
+// the archive isn't nested.
+func memberPath(member, name string) string {
+	if member == "" {
+		return name
+	}
+	return member + archiveSep + name
+}
+
+// isZip checks if name is a zip archive judging by its extension.
+func isZip(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".zip")
+}
@@ -0,0 +1,146 @@
+package scan
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile creates a file with given contents under dir.
+func writeFile(t *testing.T, dir, name string, data []byte) {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// gzipBytes compresses data with gzip.
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// tarBytes creates a tar archive with given files.
+func tarBytes(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, data := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// zipBytes creates a zip archive with given files.
+func zipBytes(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "plain.log", []byte("ok\ncard 4111111111111111\n"))
+	writeFile(t, dir, "clean.txt", []byte("nothing here 1234\n"))
+	writeFile(t, dir, "app.log.gz", gzipBytes(t, []byte("pan=5555555555554444\n")))
+	writeFile(t, dir, "logs.tgz", gzipBytes(t, tarBytes(t, map[string]string{
+		"app/app.log": "\n\namex 378282246310005\n",
+	})))
+	writeFile(t, dir, "export.zip", zipBytes(t, map[string][]byte{
+		"a.csv":      []byte("id,card\n1,4012888888881881\n"),
+		"nested.zip": zipBytes(t, map[string][]byte{"b.txt": []byte("6011111111111117")}),
+	}))
+	writeFile(t, dir, "vendor/lib.txt", []byte("4111111111111111"))
+
+	matches, err := Files([]string{dir}, Options{Exclude: []string{"vendor"}, Workers: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []struct {
+		path   string
+		file   string
+		member string
+		pan    string
+		line   int
+	}{
+		{"app.log.gz", "app.log.gz", "", "5555555555554444", 1},
+		{"export.zip!a.csv", "export.zip", "a.csv", "4012888888881881", 2},
+		{"export.zip!nested.zip!b.txt", "export.zip", "nested.zip!b.txt", "6011111111111117", 1},
+		{"logs.tgz!app/app.log", "logs.tgz", "app/app.log", "378282246310005", 3},
+		{"plain.log", "plain.log", "", "4111111111111111", 2},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("expected %d matches, got %+v", len(want), matches)
+	}
+	for i, w := range want {
+		m := matches[i]
+		if m.Path != filepath.Join(dir, w.path) || m.File != filepath.Join(dir, w.file) || m.Member != w.member ||
+			m.PAN != w.pan || m.Line != w.line {
+			t.Errorf("match %d: got %+v", i, m)
+		}
+	}
+}
+
+func TestFilesInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.log", []byte("4111111111111111"))
+	writeFile(t, dir, "b.txt", []byte("5555555555554444"))
+
+	matches, err := Files([]string{dir}, Options{Include: []string{"*.txt"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(matches) != 1 || matches[0].PAN != "5555555555554444" {
+		t.Errorf("unexpected matches: %+v", matches)
+	}
+}
+
+func TestFilesErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.log", []byte("4111111111111111"))
+	writeFile(t, dir, "broken.gz", []byte("not gzip"))
+
+	matches, err := Files([]string{dir, filepath.Join(dir, "missing")}, Options{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(matches) != 1 {
+		t.Errorf("expected matches from readable files, got %+v", matches)
+	}
+}
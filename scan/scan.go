@@ -0,0 +1,329 @@
+// Package scan finds credit card numbers in arbitrary text such as logs, tickets and CSV exports.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate/internal/luhn"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+// Card number length limits for candidates.
+const (
+	minDigits = 13
+	maxDigits = 19
+)
+
+// contextWindow is how many bytes before a candidate are searched for context keywords.
+const contextWindow = 32
+
+// maxSegment is the longest piece of a line that is kept in memory at once.
+const maxSegment = 64 * 1024
+
+// Match is a card number found in text.
+type Match struct {
+	PAN    string        // Card number digits.
+	Raw    string        // Card number as found in text, possibly with separators.
+	Offset int64         // Byte offset of Raw in the stream.
+	Line   int           // 1-based line number.
+	Column int           // 1-based byte column.
+	Issuer issuer.Issuer // Card issuer.
+	Score  float64       // Confidence that the match is a real card number, from 0 to 1.
+}
+
+// Scanner reads text and finds card numbers that pass Luhn's check and belong to a known issuer.
+// It is used like bufio.Scanner:
+//
+//	s := scan.NewScanner(r)
+//	for s.Scan() {
+//		m := s.Match()
+//	}
+//	if err := s.Err(); err != nil {
+//		...
+//	}
+type Scanner struct {
+	// MinScore is the lowest score a match must have to be reported.
+	MinScore float64
+
+	r         *bufio.Reader
+	offset    int64 // Stream offset of segment.
+	line      int   // Line number of segment.
+	lineStart int64 // Stream offset of the current line.
+	segment   []byte
+	pending   []Match
+	match     Match
+	err       error
+	done      bool
+}
+
+// NewScanner returns a new Scanner that reads from r.
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{
+		r:    bufio.NewReaderSize(r, maxSegment),
+		line: 1,
+	}
+}
+
+// Scan advances to the next match, which is then available through Match. It returns false
+// when there are no more matches or an error occurred.
+func (s *Scanner) Scan() bool {
+	for len(s.pending) == 0 {
+		if s.done {
+			return false
+		}
+		s.readSegment()
+	}
+	s.match, s.pending = s.pending[0], s.pending[1:]
+	return true
+}
+
+// Match returns the most recent match found by Scan.
+func (s *Scanner) Match() Match {
+	return s.match
+}
+
+// Err returns the first non-EOF error encountered by the Scanner.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// readSegment reads the next line, or the next piece of it if it's too long, and finds
+// matches in it. A trailing candidate of an incomplete line is carried over to the next segment.
+func (s *Scanner) readSegment() {
+	chunk, err := s.r.ReadSlice('\n')
+	s.segment = append(s.segment, chunk...)
+
+	switch {
+	case errors.Is(err, bufio.ErrBufferFull):
+		// Keep the trailing candidate characters since a card number may continue in the next chunk.
+		keep := len(s.segment)
+		for keep > 0 && isCandidateByte(s.segment[keep-1]) && len(s.segment)-keep < 2*maxDigits {
+			keep--
+		}
+		if keep == 0 {
+			keep = len(s.segment)
+		}
+		s.find(s.segment[:keep])
+		s.offset += int64(keep)
+		s.segment = append(s.segment[:0], s.segment[keep:]...)
+		return
+	case err == io.EOF:
+		s.done = true
+	case err != nil:
+		s.err, s.done = err, true
+		return
+	}
+
+	s.find(s.segment)
+	s.offset += int64(len(s.segment))
+	s.line++
+	s.lineStart = s.offset
+	s.segment = s.segment[:0]
+}
+
+// find looks for matches in text, which starts at s.offset in the stream.
+func (s *Scanner) find(text []byte) {
+	for i := 0; i < len(text); {
+		if !isDigit(text[i]) || (i > 0 && isWordByte(text[i-1])) {
+			i++
+			continue
+		}
+
+		groups := candidate(text, i)
+		s.findGroups(text, groups)
+		i = groups[len(groups)-1][1]
+	}
+}
+
+// findGroups looks for matches in a run of digit groups. If the whole run isn't a card number,
+// the longest sub-runs that start and end on group boundaries are tried instead, so a card
+// number next to another number, e.g. "qty 2 4111 1111 1111 1111", is still found.
+func (s *Scanner) findGroups(text []byte, groups [][2]int) {
+	for i := 0; i < len(groups); {
+		var (
+			found Match
+			next  int
+		)
+		digits := 0
+		for j := i; j < len(groups); j++ {
+			digits += groups[j][1] - groups[j][0]
+			if digits > maxDigits {
+				break
+			}
+			if m, ok := s.evaluate(text, groups[i:j+1]); ok {
+				found, next = m, j+1
+			}
+		}
+		if next == 0 {
+			i++
+			continue
+		}
+		s.pending = append(s.pending, found)
+		i = next
+	}
+}
+
+// candidate returns the digit groups of a run starting at i that may contain single space or
+// dash separators, as start and end offsets in text.
+func candidate(text []byte, i int) [][2]int {
+	var groups [][2]int
+	start := i
+	j := i
+	for ; j < len(text); j++ {
+		c := text[j]
+		if isDigit(c) {
+			continue
+		}
+		if (c == ' ' || c == '-') && j > start && j+1 < len(text) && isDigit(text[j+1]) {
+			groups = append(groups, [2]int{start, j})
+			start = j + 1
+			continue
+		}
+		break
+	}
+	return append(groups, [2]int{start, j})
+}
+
+// evaluate checks if the digit groups are a card number and scores them.
+func (s *Scanner) evaluate(text []byte, groups [][2]int) (Match, bool) {
+	start, end := groups[0][0], groups[len(groups)-1][1]
+	var (
+		b     strings.Builder
+		sizes []int
+		sep   byte
+	)
+	for k, g := range groups {
+		b.Write(text[g[0]:g[1]])
+		sizes = append(sizes, g[1]-g[0])
+		if k == 0 {
+			continue
+		}
+		// Separators are single bytes, so the one before the group is right in front of it.
+		switch c := text[g[0]-1]; sep {
+		case 0:
+			sep = c
+		case c:
+		default:
+			sep = '?'
+		}
+	}
+	digits := b.String()
+
+	if len(digits) < minDigits || len(digits) > maxDigits {
+		return Match{}, false
+	}
+	if end < len(text) && isWordByte(text[end]) {
+		return Match{}, false
+	}
+	// Part of a decimal number or a dotted identifier.
+	if (start > 0 && text[start-1] == '.') || (end+1 < len(text) && text[end] == '.' && isDigit(text[end+1])) {
+		return Match{}, false
+	}
+	if !luhn.Valid(digits) {
+		return Match{}, false
+	}
+	iss := issuer.Identify(digits)
+	if iss == issuer.Unknown {
+		return Match{}, false
+	}
+
+	m := Match{
+		PAN:    digits,
+		Raw:    string(text[start:end]),
+		Offset: s.offset + int64(start),
+		Line:   s.line,
+		Column: int(s.offset + int64(start) - s.lineStart + 1),
+		Issuer: iss,
+		Score:  score(text, start, end, sizes, sep),
+	}
+	if m.Score < s.MinScore {
+		return Match{}, false
+	}
+	return m, true
+}
+
+// Context keywords that make a candidate more or less likely to be a card number.
+var (
+	positiveKeywords = []string{"card", "pan", "cc", "visa", "mastercard", "amex", "credit", "debit", "acct", "account"}
+	negativeKeywords = []string{"order", "invoice", "tracking", "ref", "phone", "tel", "fax", "ts", "time", "timestamp", "isbn", "sku", "id", "uuid", "serial"}
+)
+
+// score estimates how likely the candidate at text[start:end] is a real card number.
+func score(text []byte, start, end int, groups []int, sep byte) float64 {
+	score := 0.6
+
+	switch {
+	case len(groups) == 1:
+	case sep == '?':
+		score -= 0.3
+	case typicalGrouping(groups):
+		score += 0.2
+	default:
+		score -= 0.2
+	}
+
+	before := strings.ToLower(string(text[max(0, start-contextWindow):start]))
+	words := strings.FieldsFunc(before, func(r rune) bool {
+		return !('a' <= r && r <= 'z')
+	})
+	// Only the closest few words are considered.
+	if len(words) > 3 {
+		words = words[len(words)-3:]
+	}
+	for _, w := range words {
+		for _, k := range positiveKeywords {
+			if w == k {
+				score += 0.3
+			}
+		}
+		for _, k := range negativeKeywords {
+			if w == k {
+				score -= 0.3
+			}
+		}
+	}
+
+	// Undelimited numbers followed by a time of day are likely date-based identifiers.
+	if len(groups) == 1 && startsTimestamp(text[end:]) {
+		score -= 0.2
+	}
+
+	return min(max(score, 0), 1)
+}
+
+// typicalGrouping checks if digit groups follow a common card number layout: 4-4-4-4,
+// 4-4-4-4-3, 4-6-5 or 4-6-4.
+func typicalGrouping(groups []int) bool {
+	switch {
+	case len(groups) >= 4 && groups[0] == 4 && groups[1] == 4 && groups[2] == 4 && groups[3] == 4:
+		return len(groups) == 4 || (len(groups) == 5 && groups[4] <= 3)
+	case len(groups) == 3 && groups[0] == 4 && groups[1] == 6:
+		return groups[2] == 4 || groups[2] == 5
+	}
+	return false
+}
+
+// startsTimestamp checks if text starts with a time of day, e.g. " 12:30".
+func startsTimestamp(text []byte) bool {
+	text = bytes.TrimLeft(text, " T")
+	return len(text) >= 5 && isDigit(text[0]) && isDigit(text[1]) && text[2] == ':' && isDigit(text[3]) && isDigit(text[4])
+}
+
+// isDigit checks if c is an ASCII digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isWordByte checks if c is an ASCII letter, digit or underscore.
+func isWordByte(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c == '_'
+}
+
+// isCandidateByte checks if c may be part of a card number candidate.
+func isCandidateByte(c byte) bool {
+	return isDigit(c) || c == ' ' || c == '-'
+}
@@ -0,0 +1,140 @@
+package scan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+// scanAll returns all matches found in text.
+func scanAll(t *testing.T, s *Scanner) []Match {
+	var matches []Match
+	for s.Scan() {
+		matches = append(matches, s.Match())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return matches
+}
+
+func TestScanner(t *testing.T) {
+	text := "first line\n" +
+		"card: 4111 1111 1111 1111, amex 3782-822463-10005\n" +
+		"plain 5555555555554444 and bad 4111111111111112\n" +
+		"hash a4111111111111111 float 1.4111111111111111 long 41111111111111111111\n"
+
+	matches := scanAll(t, NewScanner(strings.NewReader(text)))
+
+	want := []struct {
+		pan    string
+		raw    string
+		line   int
+		issuer issuer.Issuer
+	}{
+		{"4111111111111111", "4111 1111 1111 1111", 2, issuer.Visa},
+		{"378282246310005", "3782-822463-10005", 2, issuer.AmericanExpress},
+		{"5555555555554444", "5555555555554444", 3, issuer.MasterCard},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("expected %d matches, got %+v", len(want), matches)
+	}
+	for i, w := range want {
+		m := matches[i]
+		if m.PAN != w.pan || m.Raw != w.raw || m.Line != w.line || m.Issuer != w.issuer {
+			t.Errorf("match %d: got %+v", i, m)
+		}
+		if m.Column != int(m.Offset)-strings.LastIndexByte(text[:m.Offset], '\n') {
+			t.Errorf("match %d: unexpected column %d", i, m.Column)
+		}
+		if got := text[m.Offset : m.Offset+int64(len(m.Raw))]; got != m.Raw {
+			t.Errorf("match %d: offset %d points at %q", i, m.Offset, got)
+		}
+	}
+}
+
+func TestScannerAdjacentNumbers(t *testing.T) {
+	cases := []struct {
+		text string
+		raw  string
+	}{
+		{"card 4539983514929271 123", "4539983514929271"},
+		{"qty 2 4539983514929271", "4539983514929271"},
+		{"4539-9835-1492-9271-12", "4539-9835-1492-9271"},
+	}
+
+	for _, c := range cases {
+		matches := scanAll(t, NewScanner(strings.NewReader(c.text)))
+		if len(matches) != 1 || matches[0].PAN != "4539983514929271" || matches[0].Raw != c.raw {
+			t.Errorf("%q: unexpected matches %+v", c.text, matches)
+			continue
+		}
+		if got := c.text[matches[0].Offset:]; !strings.HasPrefix(got, c.raw) {
+			t.Errorf("%q: offset %d points at %q", c.text, matches[0].Offset, got)
+		}
+	}
+}
+
+func TestScannerScore(t *testing.T) {
+	cases := []struct {
+		text string
+		min  float64
+		max  float64
+	}{
+		{"visa card 4111111111111111", 0.9, 1},
+		{"4111-1111-1111-1111", 0.75, 0.85},
+		{"4111111111111111", 0.55, 0.65},
+		{"order id 4111111111111111", 0, 0.1},
+		{"41111-11111-111111", 0.35, 0.45},
+		{"4111 1111-1111 1111", 0.25, 0.35},
+	}
+
+	for _, c := range cases {
+		matches := scanAll(t, NewScanner(strings.NewReader(c.text)))
+		if len(matches) != 1 {
+			t.Errorf("%q: expected a match, got %+v", c.text, matches)
+			continue
+		}
+		if s := matches[0].Score; s < c.min || s > c.max {
+			t.Errorf("%q: score %.2f not in [%.2f, %.2f]", c.text, s, c.min, c.max)
+		}
+	}
+}
+
+func TestScannerMinScore(t *testing.T) {
+	s := NewScanner(strings.NewReader("invoice 4111111111111111\ncard 5555555555554444\n"))
+	s.MinScore = 0.5
+
+	matches := scanAll(t, s)
+	if len(matches) != 1 || matches[0].PAN != "5555555555554444" {
+		t.Errorf("unexpected matches: %+v", matches)
+	}
+}
+
+func TestScannerLongLine(t *testing.T) {
+	// Place a card number across the reader's buffer boundary.
+	prefix := strings.Repeat("x", maxSegment-8) + " "
+	text := prefix + "4111 1111 1111 1111 tail"
+
+	matches := scanAll(t, NewScanner(iotest.OneByteReader(strings.NewReader(text))))
+	if len(matches) != 1 {
+		t.Fatalf("expected a match, got %+v", matches)
+	}
+	if m := matches[0]; m.Offset != int64(len(prefix)) || m.Column != len(prefix)+1 || m.Raw != "4111 1111 1111 1111" {
+		t.Errorf("unexpected match: %+v", m)
+	}
+}
+
+func TestScannerError(t *testing.T) {
+	errRead := errors.New("read failed")
+	s := NewScanner(iotest.ErrReader(errRead))
+	if s.Scan() {
+		t.Fatal("expected no matches")
+	}
+	if !errors.Is(s.Err(), errRead) {
+		t.Errorf("expected read error, got %v", s.Err())
+	}
+}
@@ -0,0 +1,84 @@
+package cardvalidate
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChargeError is returned by ValidateSchedule when the card won't be valid on a charge date.
+type ChargeError struct {
+	Date time.Time // Date of the first charge that will fail.
+	Err  error
+}
+
+// Error implements error.
+func (e *ChargeError) Error() string {
+	return fmt.Sprintf("%s on %s", e.Err, e.Date.Format(time.DateOnly))
+}
+
+// Unwrap returns the underlying validation error.
+func (e *ChargeError) Unwrap() error {
+	return e.Err
+}
+
+// MonthlySchedule returns n monthly charge dates starting at first. Days that don't exist in
+// shorter months are clamped to the last day of the month.
+func MonthlySchedule(first time.Time, n int) []time.Time {
+	dates := make([]time.Time, 0, n)
+	for i := range n {
+		year, month, day := first.Date()
+		month += time.Month(i)
+		if last := daysIn(year, month); day > last {
+			day = last
+		}
+		h, m, s := first.Clock()
+		dates = append(dates, time.Date(year, month, day, h, m, s, first.Nanosecond(), first.Location()))
+	}
+	return dates
+}
+
+// daysIn returns the number of days in month of year. month may be out of the 1-12 range.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// ValidateSchedule is like ValidateAt for each of the charge dates, which are expected to be
+// in chronological order. The card is fully validated as of the first charge and only its
+// expiration date is checked against the rest. If the card has expired by one of the charges,
+// a *ChargeError for the first such charge is returned.
+func ValidateSchedule(cardNumber, expDate string, charges []time.Time) (Result, error) {
+	var v Validator
+	return v.ValidateSchedule(cardNumber, expDate, charges)
+}
+
+// ValidateSchedule is like the package-level ValidateSchedule but uses v's configuration.
+func (v *Validator) ValidateSchedule(cardNumber, expDate string, charges []time.Time) (Result, error) {
+	return v.ValidatePANSchedule(NewPAN(cardNumber), expDate, charges)
+}
+
+// ValidatePANSchedule is like ValidateSchedule but takes the card number as a PAN.
+func (v *Validator) ValidatePANSchedule(pan PAN, expDate string, charges []time.Time) (Result, error) {
+	if len(charges) == 0 {
+		return v.ValidatePAN(pan, expDate)
+	}
+
+	res, err := v.validate(pan, expDate, charges[0].UTC())
+	if err != nil {
+		if err == ErrCardExpired {
+			err = &ChargeError{Date: charges[0], Err: err}
+		}
+		return res, err
+	}
+
+	exp, err := parseExpDate(expDate)
+	if err != nil {
+		return res, err
+	}
+
+	for _, date := range charges[1:] {
+		if expiredAt(exp, date.UTC()) {
+			return res, &ChargeError{Date: date, Err: ErrCardExpired}
+		}
+	}
+	return res, nil
+}
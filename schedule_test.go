@@ -0,0 +1,58 @@
+package cardvalidate
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMonthlySchedule(t *testing.T) {
+	first := time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)
+	want := []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"}
+
+	have := MonthlySchedule(first, len(want))
+	if len(have) != len(want) {
+		t.Fatalf("unexpected schedule length: want %d have %d", len(want), len(have))
+	}
+	for i, date := range have {
+		if date.Format(time.DateOnly) != want[i] {
+			t.Errorf("charge %d date mismatch: want %s have %s", i, want[i], date.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestValidateAt(t *testing.T) {
+	at := time.Date(2027, 7, 15, 0, 0, 0, 0, time.UTC)
+	if _, err := ValidateAt("4539983514929271", "08/2027", at); err != nil {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+	if _, err := ValidateAt("4539983514929271", "07/2027", at); !errors.Is(err, ErrCardExpired) {
+		t.Errorf("unexpected error: want %s have %v", ErrCardExpired, err)
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	charges := MonthlySchedule(time.Date(2025, 10, 10, 0, 0, 0, 0, time.UTC), 6)
+
+	if _, err := ValidateSchedule("4539983514929271", "09/2027", charges); err != nil {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+
+	_, err := ValidateSchedule("4539983514929271", "01/2026", charges)
+	var chargeErr *ChargeError
+	if !errors.As(err, &chargeErr) || !errors.Is(err, ErrCardExpired) {
+		t.Fatalf("expected a ChargeError wrapping ErrCardExpired, got %v", err)
+	}
+	if want := "2026-01-10"; chargeErr.Date.Format(time.DateOnly) != want {
+		t.Errorf("failing charge date mismatch: want %s have %s", want, chargeErr.Date.Format(time.DateOnly))
+	}
+
+	_, err = ValidateSchedule("4539983514929271", "10/2025", charges)
+	if !errors.As(err, &chargeErr) || !chargeErr.Date.Equal(charges[0]) {
+		t.Errorf("expected the first charge to fail, got %v", err)
+	}
+
+	if _, err := ValidateSchedule("4539983514929272", "09/2027", charges); !errors.Is(err, ErrInvalidAccountNumber) {
+		t.Errorf("unexpected error: want %s have %v", ErrInvalidAccountNumber, err)
+	}
+}
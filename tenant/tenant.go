@@ -0,0 +1,237 @@
+// Package tenant manages private-label issuer ranges registered at runtime on behalf of tenants.
+package tenant
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+var (
+	ErrInvalidTenant = errors.New("tenant: invalid tenant ID")
+	ErrInvalidIssuer = errors.New("tenant: invalid issuer")
+	ErrNotFound      = errors.New("tenant: issuer not found")
+)
+
+// Limits on IIN ranges, which are expanded into one registry key per IIN.
+const (
+	maxIINDigits     = 8     // IINs are at most 8 digits long.
+	maxIINsPerIssuer = 10000 // Total number of IINs in an issuer's ranges.
+)
+
+// tenantIDPattern matches valid tenant IDs.
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Issuer is a private-label card issuer registered by a tenant.
+type Issuer struct {
+	Name     string            `json:"name"`
+	Ranges   []issuer.IntRange `json:"ranges"`   // IIN ranges.
+	Length   issuer.IntRange   `json:"length"`   // Card number length.
+	Checksum string            `json:"checksum"` // Check digit algorithm: luhn or none.
+}
+
+// entries converts i into issuer registry entries.
+func (i Issuer) entries() ([]issuer.Entry, error) {
+	if i.Name == "" {
+		return nil, fmt.Errorf("%w: missing name", ErrInvalidIssuer)
+	}
+	if len(i.Ranges) == 0 {
+		return nil, fmt.Errorf("%w: %s: no IIN ranges", ErrInvalidIssuer, i.Name)
+	}
+	if i.Length.Start < 8 || i.Length.End > 19 || i.Length.Start > i.Length.End {
+		return nil, fmt.Errorf("%w: %s: card number length must be within 8-19", ErrInvalidIssuer, i.Name)
+	}
+
+	checksum, ok := issuer.ChecksumByName(i.Checksum)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: unknown checksum %q", ErrInvalidIssuer, i.Name, i.Checksum)
+	}
+
+	entries := make([]issuer.Entry, 0, len(i.Ranges))
+	iins := 0
+	for _, r := range i.Ranges {
+		if r.Start <= 0 || r.Start > r.End || len(strconv.Itoa(r.End)) > maxIINDigits {
+			return nil, fmt.Errorf("%w: %s: invalid IIN range %d-%d", ErrInvalidIssuer, i.Name, r.Start, r.End)
+		}
+		if iins += r.End - r.Start + 1; iins > maxIINsPerIssuer {
+			return nil, fmt.Errorf("%w: %s: IIN ranges span more than %d IINs", ErrInvalidIssuer, i.Name, maxIINsPerIssuer)
+		}
+		entries = append(entries, issuer.Entry{
+			Issuer:   issuer.PrivateLabel,
+			Name:     i.Name,
+			Prefix:   r,
+			Length:   i.Length,
+			Checksum: checksum,
+		})
+	}
+	return entries, nil
+}
+
+// Store keeps tenants' private-label issuers and persists them to a local JSON file.
+// It is safe for concurrent use.
+type Store struct {
+	mu         sync.RWMutex
+	path       string
+	tenants    map[string][]Issuer
+	registries map[string]*issuer.Registry
+}
+
+// Open loads a store from path. A missing file yields an empty store, and an empty path
+// yields a store that is kept in memory only.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:       path,
+		tenants:    make(map[string][]Issuer),
+		registries: make(map[string]*issuer.Registry),
+	}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var tenants map[string][]Issuer
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("error decoding tenant store %s: %w", path, err)
+	}
+	for id, issuers := range tenants {
+		reg, err := buildRegistry(issuers)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", id, err)
+		}
+		s.tenants[id] = issuers
+		s.registries[id] = reg
+	}
+	return s, nil
+}
+
+// Registry returns the issuer registry for tenantID, or nil if the tenant has no issuers.
+func (s *Store) Registry(tenantID string) *issuer.Registry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.registries[tenantID]
+}
+
+// Issuers returns issuers registered by tenantID.
+func (s *Store) Issuers(tenantID string) []Issuer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.tenants[tenantID])
+}
+
+// Put registers iss for tenantID, replacing an existing issuer with the same name.
+func (s *Store) Put(tenantID string, iss Issuer) error {
+	if !tenantIDPattern.MatchString(tenantID) {
+		return ErrInvalidTenant
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issuers := slices.DeleteFunc(slices.Clone(s.tenants[tenantID]), func(i Issuer) bool {
+		return i.Name == iss.Name
+	})
+	return s.update(tenantID, append(issuers, iss))
+}
+
+// Delete removes the issuer with given name from tenantID.
+func (s *Store) Delete(tenantID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issuers := s.tenants[tenantID]
+	i := slices.IndexFunc(issuers, func(i Issuer) bool { return i.Name == name })
+	if i < 0 {
+		return ErrNotFound
+	}
+	return s.update(tenantID, slices.Delete(slices.Clone(issuers), i, i+1))
+}
+
+// update replaces tenantID's issuers and saves the store. Callers must hold s.mu.
+func (s *Store) update(tenantID string, issuers []Issuer) error {
+	var reg *issuer.Registry
+	if len(issuers) > 0 {
+		var err error
+		if reg, err = buildRegistry(issuers); err != nil {
+			return err
+		}
+	}
+
+	prevIssuers, prevReg := s.tenants[tenantID], s.registries[tenantID]
+	if len(issuers) > 0 {
+		s.tenants[tenantID], s.registries[tenantID] = issuers, reg
+	} else {
+		delete(s.tenants, tenantID)
+		delete(s.registries, tenantID)
+	}
+
+	if err := s.save(); err != nil {
+		// Roll back so that memory doesn't diverge from disk.
+		if prevIssuers != nil {
+			s.tenants[tenantID], s.registries[tenantID] = prevIssuers, prevReg
+		} else {
+			delete(s.tenants, tenantID)
+			delete(s.registries, tenantID)
+		}
+		return err
+	}
+	return nil
+}
+
+// save atomically writes the store to its file. Callers must hold s.mu.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.tenants, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+// buildRegistry builds an issuer registry from issuers.
+func buildRegistry(issuers []Issuer) (*issuer.Registry, error) {
+	var entries []issuer.Entry
+	for _, iss := range issuers {
+		e, err := iss.entries()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e...)
+	}
+
+	reg, err := issuer.NewRegistry(entries)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIssuer, err)
+	}
+	return reg, nil
+}
@@ -0,0 +1,141 @@
+package tenant
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+var acmeFleet = Issuer{
+	Name:     "Acme Fleet",
+	Ranges:   []issuer.IntRange{issuer.NewIntRange(7000, 7009)},
+	Length:   issuer.NewSingleIntRange(16),
+	Checksum: "none",
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("error opening store: %s", err)
+	}
+	if err := s.Put("acme", acmeFleet); err != nil {
+		t.Fatalf("error registering issuer: %s", err)
+	}
+
+	// Reopen the store to make sure that it was persisted.
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("error reopening store: %s", err)
+	}
+
+	reg := s.Registry("acme")
+	if reg == nil {
+		t.Fatalf("missing registry for tenant")
+	}
+	entry, ok := reg.LookupAt("7005123412341234", time.Now())
+	if !ok || entry.IssuerName() != "Acme Fleet" || entry.Checksum.Name() != "none" {
+		t.Fatalf("unexpected lookup result: %+v", entry)
+	}
+
+	if s.Registry("other") != nil {
+		t.Fatalf("expected other tenants to have no registry")
+	}
+
+	if err := s.Delete("acme", "Acme Fleet"); err != nil {
+		t.Fatalf("error deleting issuer: %s", err)
+	}
+	if err := s.Delete("acme", "Acme Fleet"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if s.Registry("acme") != nil {
+		t.Fatalf("expected registry to be removed with the last issuer")
+	}
+}
+
+func TestStoreInvalid(t *testing.T) {
+	s, err := Open("")
+	if err != nil {
+		t.Fatalf("error opening store: %s", err)
+	}
+
+	if err := s.Put("not a tenant!", acmeFleet); !errors.Is(err, ErrInvalidTenant) {
+		t.Errorf("expected ErrInvalidTenant, got %v", err)
+	}
+
+	tests := []Issuer{
+		{Ranges: acmeFleet.Ranges, Length: acmeFleet.Length, Checksum: "luhn"},
+		{Name: "No ranges", Length: acmeFleet.Length, Checksum: "luhn"},
+		{Name: "Bad length", Ranges: acmeFleet.Ranges, Length: issuer.NewIntRange(4, 25), Checksum: "luhn"},
+		{Name: "Bad checksum", Ranges: acmeFleet.Ranges, Length: acmeFleet.Length, Checksum: "crc32"},
+		{Name: "Bad range", Ranges: []issuer.IntRange{issuer.NewIntRange(9, 1)}, Length: acmeFleet.Length, Checksum: "luhn"},
+		{Name: "Huge range", Ranges: []issuer.IntRange{issuer.NewIntRange(1, 999999999)}, Length: acmeFleet.Length, Checksum: "luhn"},
+		{Name: "Long IIN", Ranges: []issuer.IntRange{issuer.NewSingleIntRange(123456789)}, Length: acmeFleet.Length, Checksum: "luhn"},
+		{Name: "Too many IINs", Ranges: []issuer.IntRange{issuer.NewIntRange(100000, 105999), issuer.NewIntRange(200000, 205999)}, Length: acmeFleet.Length, Checksum: "luhn"},
+	}
+	for _, tc := range tests {
+		if err := s.Put("acme", tc); !errors.Is(err, ErrInvalidIssuer) {
+			t.Errorf("expected ErrInvalidIssuer for %+v, got %v", tc, err)
+		}
+	}
+
+	if err := s.Put("acme", acmeFleet); err != nil {
+		t.Fatalf("error registering issuer: %s", err)
+	}
+	overlapping := acmeFleet
+	overlapping.Name = "Overlapping"
+	if err := s.Put("acme", overlapping); !errors.Is(err, ErrInvalidIssuer) {
+		t.Errorf("expected ErrInvalidIssuer for overlapping ranges, got %v", err)
+	}
+	if n := len(s.Issuers("acme")); n != 1 {
+		t.Errorf("unexpected issuer count after failed update: want 1 have %d", n)
+	}
+}
+
+func TestStoreNestedRanges(t *testing.T) {
+	s, err := Open("")
+	if err != nil {
+		t.Fatalf("error opening store: %s", err)
+	}
+
+	// A tenant range nested in a built-in one, and a tenant range nested in another.
+	if err := s.Put("acme", Issuer{
+		Name:     "Acme Store",
+		Ranges:   []issuer.IntRange{issuer.NewSingleIntRange(60)},
+		Length:   issuer.NewSingleIntRange(18),
+		Checksum: "luhn",
+	}); err != nil {
+		t.Fatalf("error registering issuer: %s", err)
+	}
+	if err := s.Put("acme", Issuer{
+		Name:     "Acme Fleet",
+		Ranges:   []issuer.IntRange{issuer.NewSingleIntRange(6011)},
+		Length:   issuer.NewSingleIntRange(17),
+		Checksum: "none",
+	}); err != nil {
+		t.Fatalf("error registering nested issuer: %s", err)
+	}
+
+	v := cardvalidate.Validator{Registry: s.Registry("acme")}
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"60111111111111111", "Acme Fleet"},
+		{"601111111111111114", "Acme Store"},
+		{"6011111111111117", "Discover"}, // Built-in range for other lengths.
+	}
+	for _, tc := range tests {
+		res, err := v.Validate(tc.number, "12/2049")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.number, err)
+		} else if res.IssuerName != tc.want {
+			t.Errorf("%s: want %s have %s", tc.number, tc.want, res.IssuerName)
+		}
+	}
+}
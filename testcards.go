@@ -0,0 +1,130 @@
+package cardvalidate
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// ErrTestCard is returned for well-known test card numbers when test cards are rejected.
+var ErrTestCard = errors.New("cardvalidate: test card number")
+
+// CodeTestCard is reported for well-known test card numbers when test cards are flagged.
+const CodeTestCard = "test_card"
+
+// TestCardMode controls how validation treats well-known test card numbers.
+type TestCardMode int
+
+const (
+	TestCardsAllow  TestCardMode = iota // Treat test cards like any other card.
+	TestCardsWarn                       // Report a CodeTestCard warning.
+	TestCardsReject                     // Fail validation with ErrTestCard.
+)
+
+// String implements fmt.Stringer
+func (m TestCardMode) String() string {
+	switch m {
+	case TestCardsAllow:
+		return "allow"
+	case TestCardsWarn:
+		return "warn"
+	case TestCardsReject:
+		return "reject"
+	default:
+		return fmt.Sprintf("TestCardMode(%d)", m)
+	}
+}
+
+// ParseTestCardMode parses a test card mode from its name.
+func ParseTestCardMode(s string) (TestCardMode, error) {
+	switch s {
+	case "allow":
+		return TestCardsAllow, nil
+	case "warn":
+		return TestCardsWarn, nil
+	case "reject":
+		return TestCardsReject, nil
+	default:
+		return 0, fmt.Errorf("cardvalidate: unknown test card mode %q", s)
+	}
+}
+
+// TestCardList is a set of published sandbox card numbers mapped to who published them.
+// It is safe for concurrent use.
+type TestCardList struct {
+	mu    sync.RWMutex
+	cards map[string]string
+}
+
+// NewTestCardList returns a list that contains the built-in test card numbers.
+func NewTestCardList() *TestCardList {
+	return &TestCardList{cards: maps.Clone(builtinTestCards)}
+}
+
+// Add adds cardNumber published by source to the list.
+func (l *TestCardList) Add(cardNumber, source string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cards[cardNumber] = source
+}
+
+// Lookup checks if cardNumber is a test card and returns who published it.
+func (l *TestCardList) Lookup(cardNumber string) (string, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	source, ok := l.cards[cardNumber]
+	return source, ok
+}
+
+// defaultTestCards is used by validators that don't have their own test card list.
+var defaultTestCards = NewTestCardList()
+
+// builtinTestCards lists sandbox card numbers published by card networks and payment processors.
+var builtinTestCards = map[string]string{
+	// Card networks.
+	"4111111111111111": "Visa",
+	"4012888888881881": "Visa",
+	"5555555555554444": "MasterCard",
+	"5105105105105100": "MasterCard",
+	"378282246310005":  "American Express",
+	"371449635398431":  "American Express",
+	"378734493671000":  "American Express",
+	"6011111111111117": "Discover",
+	"6011000990139424": "Discover",
+	"30569309025904":   "Diners Club",
+	"38520000023237":   "Diners Club",
+	"3530111333300000": "JCB",
+	"3566002020360505": "JCB",
+
+	// Stripe.
+	"4242424242424242": "Stripe",
+	"4000056655665556": "Stripe",
+	"4000002500003155": "Stripe",
+	"4000000000009995": "Stripe",
+	"4000000000000002": "Stripe",
+	"5200828282828210": "Stripe",
+	"2223003122003222": "Stripe",
+	"6011981111111113": "Stripe",
+	"36227206271667":   "Stripe",
+	"6200000000000005": "Stripe",
+
+	// Adyen.
+	"4111111145551142": "Adyen",
+	"4988438843884305": "Adyen",
+	"5555341244441115": "Adyen",
+	"2222400070000005": "Adyen",
+	"370000000000002":  "Adyen",
+	"6011601160116611": "Adyen",
+	"3569990010095841": "Adyen",
+	"36006666333344":   "Adyen",
+
+	// Braintree.
+	"4005519200000004": "Braintree",
+	"4009348888881881": "Braintree",
+	"4012000033330026": "Braintree",
+	"4012000077777777": "Braintree",
+	"4217651111111119": "Braintree",
+	"4500600000000061": "Braintree",
+	"2223000048400011": "Braintree",
+}
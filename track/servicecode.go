@@ -0,0 +1,112 @@
+package track
+
+import "fmt"
+
+// ServiceCode is a 3-digit code that tells where and how a card may be used.
+type ServiceCode string
+
+// Interchange rules, the first digit of a service code.
+type Interchange int
+
+const (
+	InterchangeInternational Interchange = iota // International interchange OK.
+	InterchangeNational                         // National interchange only.
+	InterchangeBilateral                        // No interchange except under bilateral agreement.
+	InterchangeTest                             // Test card.
+)
+
+// Authorization processing, the second digit of a service code.
+type Authorization int
+
+const (
+	AuthorizationNormal            Authorization = iota // Normal authorization.
+	AuthorizationOnline                                 // Contact issuer via online means.
+	AuthorizationOnlineNoBilateral                      // Contact issuer unless under bilateral agreement.
+)
+
+// Services allowed and PIN requirements, the third digit of a service code.
+type Services int
+
+const (
+	ServicesAny Services = iota
+	ServicesGoodsAndServices
+	ServicesATMOnly
+	ServicesCashOnly
+)
+
+// PINRequirement tells whether a PIN has to be entered.
+type PINRequirement int
+
+const (
+	PINNotRequired PINRequirement = iota
+	PINRequired
+	PINIfPEDPresent // Prompt for PIN if a PIN entry device is present.
+)
+
+// ServiceCodeInfo is a decoded service code.
+type ServiceCodeInfo struct {
+	Interchange   Interchange
+	ChipCard      bool // Integrated circuit card, chip should be used when possible.
+	Authorization Authorization
+	Services      Services
+	PIN           PINRequirement
+}
+
+// Decode decodes the service code.
+func (c ServiceCode) Decode() (ServiceCodeInfo, error) {
+	if len(c) != 3 || !isDigits(string(c)) {
+		return ServiceCodeInfo{}, fmt.Errorf("%w: invalid service code %q", ErrMalformedTrack, c)
+	}
+
+	var info ServiceCodeInfo
+	switch c[0] {
+	case '1':
+		info.Interchange = InterchangeInternational
+	case '2':
+		info.Interchange, info.ChipCard = InterchangeInternational, true
+	case '5':
+		info.Interchange = InterchangeNational
+	case '6':
+		info.Interchange, info.ChipCard = InterchangeNational, true
+	case '7':
+		info.Interchange = InterchangeBilateral
+	case '9':
+		info.Interchange = InterchangeTest
+	default:
+		return ServiceCodeInfo{}, fmt.Errorf("%w: unknown interchange digit in service code %q", ErrMalformedTrack, c)
+	}
+
+	switch c[1] {
+	case '0':
+		info.Authorization = AuthorizationNormal
+	case '2':
+		info.Authorization = AuthorizationOnline
+	case '4':
+		info.Authorization = AuthorizationOnlineNoBilateral
+	default:
+		return ServiceCodeInfo{}, fmt.Errorf("%w: unknown authorization digit in service code %q", ErrMalformedTrack, c)
+	}
+
+	switch c[2] {
+	case '0':
+		info.Services, info.PIN = ServicesAny, PINRequired
+	case '1':
+		info.Services, info.PIN = ServicesAny, PINNotRequired
+	case '2':
+		info.Services, info.PIN = ServicesGoodsAndServices, PINNotRequired
+	case '3':
+		info.Services, info.PIN = ServicesATMOnly, PINRequired
+	case '4':
+		info.Services, info.PIN = ServicesCashOnly, PINNotRequired
+	case '5':
+		info.Services, info.PIN = ServicesGoodsAndServices, PINRequired
+	case '6':
+		info.Services, info.PIN = ServicesAny, PINIfPEDPresent
+	case '7':
+		info.Services, info.PIN = ServicesGoodsAndServices, PINIfPEDPresent
+	default:
+		return ServiceCodeInfo{}, fmt.Errorf("%w: unknown services digit in service code %q", ErrMalformedTrack, c)
+	}
+
+	return info, nil
+}
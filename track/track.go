@@ -0,0 +1,168 @@
+// Package track parses magnetic stripe track 1 and track 2 data as defined in ISO/IEC 7813.
+package track
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+var (
+	ErrMalformedTrack = errors.New("track: malformed track data")
+	ErrInvalidLRC     = errors.New("track: longitudinal redundancy check failed")
+)
+
+// Data holds card information read from a magnetic stripe track.
+type Data struct {
+	PAN           string
+	Name          string // Cardholder name, only present on track 1.
+	Expiry        string // Expiration date in YYMM format.
+	ServiceCode   ServiceCode
+	Discretionary string // Issuer's discretionary data.
+}
+
+// ExpirationDate returns the expiration date in the MM/YYYY format used by cardvalidate, or an
+// empty string if Expiry isn't 4 characters long.
+func (d Data) ExpirationDate() string {
+	if len(d.Expiry) != 4 {
+		return ""
+	}
+	return d.Expiry[2:] + "/20" + d.Expiry[:2]
+}
+
+// Validate validates the card number and expiration date with cardvalidate.
+func (d Data) Validate() (cardvalidate.Result, error) {
+	return cardvalidate.ValidateCard(d.PAN, d.ExpirationDate())
+}
+
+// Parse parses either track 1 or track 2 data depending on its start sentinel.
+func Parse(raw string) (Data, error) {
+	switch {
+	case strings.HasPrefix(raw, "%"):
+		return ParseTrack1(raw)
+	case strings.HasPrefix(raw, ";"):
+		return ParseTrack2(raw)
+	default:
+		return Data{}, fmt.Errorf("%w: unknown start sentinel", ErrMalformedTrack)
+	}
+}
+
+// ParseTrack1 parses track 1 data: %B{PAN}^{NAME}^{YYMM}{service code}{discretionary}?
+// optionally followed by the LRC character, which is verified if present.
+func ParseTrack1(raw string) (Data, error) {
+	body, err := unwrap(raw, '%', 0x20, 0x3f)
+	if err != nil {
+		return Data{}, err
+	}
+
+	body, ok := strings.CutPrefix(body, "B")
+	if !ok {
+		return Data{}, fmt.Errorf("%w: unsupported format code", ErrMalformedTrack)
+	}
+
+	fields := strings.SplitN(body, "^", 3)
+	if len(fields) != 3 {
+		return Data{}, fmt.Errorf("%w: missing field separator", ErrMalformedTrack)
+	}
+
+	d, err := parseFields(fields[0], fields[2])
+	if err != nil {
+		return Data{}, err
+	}
+	if len(fields[1]) < 2 || len(fields[1]) > 26 {
+		return Data{}, fmt.Errorf("%w: invalid cardholder name length", ErrMalformedTrack)
+	}
+	d.Name = strings.TrimSpace(fields[1])
+	return d, nil
+}
+
+// ParseTrack2 parses track 2 data: ;{PAN}={YYMM}{service code}{discretionary}? optionally
+// followed by the LRC character, which is verified if present.
+func ParseTrack2(raw string) (Data, error) {
+	body, err := unwrap(raw, ';', 0x30, 0x0f)
+	if err != nil {
+		return Data{}, err
+	}
+
+	pan, rest, ok := strings.Cut(body, "=")
+	if !ok {
+		return Data{}, fmt.Errorf("%w: missing field separator", ErrMalformedTrack)
+	}
+	return parseFields(pan, rest)
+}
+
+// ParseTrack2Equivalent parses track 2 equivalent data without sentinels as found in chip card
+// records and ISO 8583 messages. Either 'D' or '=' may be used as the field separator, and
+// trailing 'F' padding is ignored.
+func ParseTrack2Equivalent(s string) (Data, error) {
+	s = strings.TrimRight(strings.ToUpper(s), "F")
+	pan, rest, ok := strings.Cut(strings.ReplaceAll(s, "D", "="), "=")
+	if !ok {
+		return Data{}, fmt.Errorf("%w: missing field separator", ErrMalformedTrack)
+	}
+	return parseFields(pan, rest)
+}
+
+// unwrap strips start and end sentinels from raw track data and verifies the LRC character if
+// present. Track characters are encoded as offset plus their value masked by mask.
+func unwrap(raw string, start byte, offset, mask byte) (string, error) {
+	if len(raw) < 2 || raw[0] != start {
+		return "", fmt.Errorf("%w: missing start sentinel", ErrMalformedTrack)
+	}
+
+	end := strings.IndexByte(raw, '?')
+	if end < 0 {
+		return "", fmt.Errorf("%w: missing end sentinel", ErrMalformedTrack)
+	}
+
+	switch len(raw) - end - 1 {
+	case 0:
+	case 1:
+		var lrc byte
+		for i := 0; i <= end; i++ {
+			if raw[i] < offset || raw[i]-offset > mask {
+				return "", fmt.Errorf("%w: invalid character %q", ErrMalformedTrack, raw[i])
+			}
+			lrc ^= raw[i] - offset
+		}
+		if raw[end+1] != offset+lrc {
+			return "", ErrInvalidLRC
+		}
+	default:
+		return "", fmt.Errorf("%w: trailing data after end sentinel", ErrMalformedTrack)
+	}
+	return raw[1:end], nil
+}
+
+// parseFields parses PAN and the data that follows the field separator, which is shared
+// between both tracks.
+func parseFields(pan, rest string) (Data, error) {
+	if len(pan) == 0 || len(pan) > 19 || !isDigits(pan) {
+		return Data{}, fmt.Errorf("%w: invalid PAN", ErrMalformedTrack)
+	}
+	if len(rest) < 7 || !isDigits(rest[:7]) {
+		return Data{}, fmt.Errorf("%w: invalid expiration date or service code", ErrMalformedTrack)
+	}
+	if month := rest[2:4]; month < "01" || month > "12" {
+		return Data{}, fmt.Errorf("%w: invalid expiration month", ErrMalformedTrack)
+	}
+
+	return Data{
+		PAN:           pan,
+		Expiry:        rest[:4],
+		ServiceCode:   ServiceCode(rest[4:7]),
+		Discretionary: rest[7:],
+	}, nil
+}
+
+// isDigits checks if s consists of ASCII digits only.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
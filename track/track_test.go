@@ -0,0 +1,145 @@
+package track
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/waterfountain1996/cardvalidate"
+)
+
+// withLRC appends the LRC character to track data.
+func withLRC(raw string, offset byte) string {
+	var lrc byte
+	for i := 0; i < len(raw); i++ {
+		lrc ^= raw[i] - offset
+	}
+	return raw + string(rune(offset+lrc))
+}
+
+func TestParseTrack1(t *testing.T) {
+	raw := "%B4111111111111111^DOE/JOHN^2812101123400001230?"
+
+	for _, s := range []string{raw, withLRC(raw, 0x20)} {
+		d, err := Parse(s)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %s", s, err)
+		}
+
+		want := Data{
+			PAN:           "4111111111111111",
+			Name:          "DOE/JOHN",
+			Expiry:        "2812",
+			ServiceCode:   "101",
+			Discretionary: "123400001230",
+		}
+		if d != want {
+			t.Fatalf("parse mismatch: want %+v have %+v", want, d)
+		}
+		if d.ExpirationDate() != "12/2028" {
+			t.Fatalf("unexpected expiration date: %s", d.ExpirationDate())
+		}
+	}
+}
+
+func TestParseTrack2(t *testing.T) {
+	raw := ";5555555555554444=28122015432112345678?"
+
+	for _, s := range []string{raw, withLRC(raw, 0x30)} {
+		d, err := Parse(s)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %s", s, err)
+		}
+		if d.PAN != "5555555555554444" || d.Expiry != "2812" || d.ServiceCode != "201" ||
+			d.Discretionary != "5432112345678" {
+			t.Fatalf("unexpected track data: %+v", d)
+		}
+
+		if _, err := d.Validate(); err != nil {
+			t.Fatalf("unexpected validation error: %s", err)
+		}
+	}
+}
+
+func TestParseTrack2Equivalent(t *testing.T) {
+	for _, s := range []string{"4111111111111111D28122011234F", "4111111111111111=28122011234"} {
+		d, err := ParseTrack2Equivalent(s)
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %s", s, err)
+		}
+		if d.PAN != "4111111111111111" || d.Expiry != "2812" || d.ServiceCode != "201" || d.Discretionary != "1234" {
+			t.Fatalf("unexpected track data: %+v", d)
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		raw string
+		err error
+	}{
+		{"", ErrMalformedTrack},
+		{"B4111111111111111^DOE/JOHN^2812101?", ErrMalformedTrack},
+		{"%A4111111111111111^DOE/JOHN^2812101?", ErrMalformedTrack},
+		{"%B4111111111111111^DOE/JOHN^2812101", ErrMalformedTrack},
+		{"%B4111111111111111^DOE/JOHN2812101?", ErrMalformedTrack},
+		{";4111111111111111=2812?", ErrMalformedTrack},
+		{";4111111111111111=2813101?", ErrMalformedTrack},
+		{";41111111111111X1=2812101?", ErrMalformedTrack},
+		{";4111111111111111=2812101?;", ErrInvalidLRC},
+		{withLRC(";4111111111111111=2812101?", 0x30) + "1", ErrMalformedTrack},
+	}
+
+	for _, tc := range tests {
+		if _, err := Parse(tc.raw); !errors.Is(err, tc.err) {
+			t.Errorf("unexpected error for %q: want %s have %v", tc.raw, tc.err, err)
+		}
+	}
+}
+
+func TestServiceCode(t *testing.T) {
+	tests := []struct {
+		code ServiceCode
+		want ServiceCodeInfo
+	}{
+		{"101", ServiceCodeInfo{Interchange: InterchangeInternational, Services: ServicesAny, PIN: PINNotRequired}},
+		{"201", ServiceCodeInfo{Interchange: InterchangeInternational, ChipCard: true, PIN: PINNotRequired}},
+		{"520", ServiceCodeInfo{Interchange: InterchangeNational, Authorization: AuthorizationOnline, PIN: PINRequired}},
+		{"606", ServiceCodeInfo{Interchange: InterchangeNational, ChipCard: true, PIN: PINIfPEDPresent}},
+		{"703", ServiceCodeInfo{Interchange: InterchangeBilateral, Services: ServicesATMOnly, PIN: PINRequired}},
+		{"947", ServiceCodeInfo{
+			Interchange:   InterchangeTest,
+			Authorization: AuthorizationOnlineNoBilateral,
+			Services:      ServicesGoodsAndServices,
+			PIN:           PINIfPEDPresent,
+		}},
+	}
+
+	for _, tc := range tests {
+		have, err := tc.code.Decode()
+		if err != nil {
+			t.Errorf("unexpected error decoding %s: %s", tc.code, err)
+			continue
+		}
+		if have != tc.want {
+			t.Errorf("decode mismatch (%s): want %+v have %+v", tc.code, tc.want, have)
+		}
+	}
+
+	for _, code := range []ServiceCode{"", "10", "301", "111", "108", "1a1"} {
+		if _, err := code.Decode(); !errors.Is(err, ErrMalformedTrack) {
+			t.Errorf("expected ErrMalformedTrack for %q, got %v", code, err)
+		}
+	}
+}
+
+func TestMalformedExpiry(t *testing.T) {
+	for _, expiry := range []string{"", "281", "28121"} {
+		d := Data{PAN: "4111111111111111", Expiry: expiry}
+		if date := d.ExpirationDate(); date != "" {
+			t.Errorf("%q: unexpected expiration date %s", expiry, date)
+		}
+		if _, err := d.Validate(); !errors.Is(err, cardvalidate.ErrMalformedDate) {
+			t.Errorf("%q: unexpected error: %v", expiry, err)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Package vault replaces card numbers with random surrogate tokens and keeps the mapping in a
+// local file encrypted with AES-GCM.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/internal/luhn"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+var (
+	ErrInvalidKey     = errors.New("vault: invalid key")
+	ErrInvalidOptions = errors.New("vault: invalid options")
+	ErrInvalidPAN     = errors.New("vault: invalid card number")
+	ErrCorrupted      = errors.New("vault: can't decrypt vault file")
+	ErrNotFound       = errors.New("vault: token not found")
+	ErrExhausted      = errors.New("vault: no free token available")
+)
+
+// KeySize is the size of the vault encryption key in bytes, the vault uses AES-256.
+const KeySize = 32
+
+// maxAttempts is how many random tokens are tried before giving up on finding a free one.
+const maxAttempts = 1000
+
+// additionalData binds vault file ciphertexts to their purpose.
+var additionalData = []byte("cardvalidate vault v1")
+
+// Options control the shape of generated tokens.
+type Options struct {
+	KeepBIN   bool // Keep the first 6 digits of the card number, requires AllowIssuerRanges.
+	KeepLast4 bool // Keep the last 4 digits of the card number.
+
+	// AllowIssuerRanges allows tokens that fall into known issuer ranges. Otherwise tokens can't
+	// be mistaken for real card numbers.
+	AllowIssuerRanges bool
+}
+
+// Vault maps card numbers to tokens and back. The same card number always gets the same token.
+// It is safe for concurrent use.
+type Vault struct {
+	mu     sync.Mutex
+	path   string
+	aead   cipher.AEAD
+	opts   Options
+	tokens map[string]string   // Card numbers by token.
+	pans   map[string][]string // Tokens by card number.
+}
+
+// Open loads a vault from path and decrypts it with a 32-byte key. A missing file yields an
+// empty vault, and an empty path yields a vault that is kept in memory only.
+func Open(path string, key []byte, opts Options) (*Vault, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: must be %d bytes", ErrInvalidKey, KeySize)
+	}
+	if opts.KeepBIN && !opts.AllowIssuerRanges {
+		return nil, fmt.Errorf("%w: tokens that keep the BIN fall into issuer ranges", ErrInvalidOptions)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKey, err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Vault{
+		path:   path,
+		aead:   aead,
+		opts:   opts,
+		tokens: make(map[string]string),
+		pans:   make(map[string][]string),
+	}
+	if path == "" {
+		return v, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return v, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	n := aead.NonceSize()
+	if len(data) < n {
+		return nil, ErrCorrupted
+	}
+	plaintext, err := aead.Open(nil, data[:n], data[n:], additionalData)
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	if err := json.Unmarshal(plaintext, &v.tokens); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCorrupted, err)
+	}
+	clear(plaintext)
+
+	for token, pan := range v.tokens {
+		v.pans[pan] = append(v.pans[pan], token)
+	}
+	return v, nil
+}
+
+// Tokenize returns the token for pan, generating and storing a new one if pan hasn't been
+// tokenized before or its tokens fall into the ranges of reg. Tokens have the same length as pan and pass Luhn's check.
+// Tokenize doesn't validate pan beyond its format, callers should validate it first.
+// Unless the vault allows issuer ranges, new tokens avoid the built-in ranges and those of reg,
+// e.g. a tenant's private-label ranges, if it isn't nil.
+func (v *Vault) Tokenize(pan cardvalidate.PAN, reg *issuer.Registry) (string, error) {
+	if !isCardNumber(pan.Bytes()) {
+		return "", ErrInvalidPAN
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for _, token := range v.pans[string(pan.Bytes())] {
+		// A token generated for another tenant may fall into one of this tenant's ranges.
+		if v.opts.AllowIssuerRanges || !inIssuerRange(token, reg) {
+			return token, nil
+		}
+	}
+
+	token, err := v.newToken(pan.Bytes(), reg)
+	if err != nil {
+		return "", err
+	}
+
+	digits := pan.Raw()
+	v.tokens[token], v.pans[digits] = digits, append(v.pans[digits], token)
+	if err := v.save(); err != nil {
+		// Roll back so that memory doesn't diverge from disk.
+		delete(v.tokens, token)
+		if tokens := v.pans[digits][:len(v.pans[digits])-1]; len(tokens) > 0 {
+			v.pans[digits] = tokens
+		} else {
+			delete(v.pans, digits)
+		}
+		return "", err
+	}
+	return token, nil
+}
+
+// Detokenize returns the card number for token.
+func (v *Vault) Detokenize(token string) (cardvalidate.PAN, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	pan, ok := v.tokens[token]
+	if !ok {
+		return cardvalidate.PAN{}, ErrNotFound
+	}
+	return cardvalidate.NewPAN(pan), nil
+}
+
+// Len returns the number of tokens in the vault.
+func (v *Vault) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.tokens)
+}
+
+// newToken generates a random token for pan that isn't in use. Callers must hold v.mu.
+func (v *Vault) newToken(pan []byte, reg *issuer.Registry) (string, error) {
+	n := len(pan)
+	start, end := 0, n
+	if v.opts.KeepBIN {
+		start = 6
+	}
+	if v.opts.KeepLast4 {
+		end = n - 4
+	}
+	if end-start < 4 {
+		return "", fmt.Errorf("%w: too short to generate a token", ErrInvalidPAN)
+	}
+
+	b := slices.Clone(pan)
+	defer clear(b)
+	for range maxAttempts {
+		if err := randomDigits(b[start:end]); err != nil {
+			return "", err
+		}
+		fixLuhn(b, end-1)
+
+		token := string(b)
+		if token == string(pan) {
+			continue
+		}
+		if !v.opts.AllowIssuerRanges && inIssuerRange(token, reg) {
+			continue
+		}
+		if _, ok := v.tokens[token]; ok {
+			continue
+		}
+		return token, nil
+	}
+	return "", ErrExhausted
+}
+
+// fixLuhn sets the digit at i so that b passes Luhn's check.
+func fixLuhn(b []byte, i int) {
+	for d := byte('0'); d <= '9'; d++ {
+		b[i] = d
+		if luhn.Valid(b) {
+			return
+		}
+	}
+}
+
+// randomDigits fills b with uniformly random ASCII digits.
+func randomDigits(b []byte) error {
+	buf := make([]byte, len(b))
+	for i := 0; i < len(b); {
+		if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+			return err
+		}
+		for _, c := range buf {
+			// Reject values that would bias the distribution of digits.
+			if c >= 250 || i == len(b) {
+				continue
+			}
+			b[i] = '0' + c%10
+			i++
+		}
+	}
+	return nil
+}
+
+// save atomically writes the encrypted vault to its file. Callers must hold v.mu.
+func (v *Vault) save() error {
+	if v.path == "" {
+		return nil
+	}
+
+	plaintext, err := json.Marshal(v.tokens)
+	if err != nil {
+		return err
+	}
+	defer clear(plaintext)
+
+	nonce := make([]byte, v.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	data := v.aead.Seal(nonce, nonce, plaintext, additionalData)
+
+	f, err := os.CreateTemp(filepath.Dir(v.path), filepath.Base(v.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), v.path)
+}
+
+// inIssuerRange checks if token falls into a built-in issuer range or one of reg's.
+func inIssuerRange(token string, reg *issuer.Registry) bool {
+	now := time.Now().UTC()
+	if reg != nil && len(reg.MatchAt(token, now)) > 0 {
+		return true
+	}
+	return len(issuer.MatchAt(token, now)) > 0
+}
+
+// isCardNumber checks if s consists of 8 to 19 digits.
+func isCardNumber(s []byte) bool {
+	if len(s) < 8 || len(s) > 19 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/waterfountain1996/cardvalidate"
+	"github.com/waterfountain1996/cardvalidate/internal/luhn"
+	"github.com/waterfountain1996/cardvalidate/issuer"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, KeySize)
+
+func TestVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.bin")
+	v, err := Open(path, testKey, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	const number = "4539983514929271"
+	token, err := v.Tokenize(cardvalidate.NewPAN(number), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(token) != len(number) || token == number || !luhn.Valid(token) {
+		t.Errorf("unexpected token: %s", token)
+	}
+	if entries := issuer.MatchAt(token, time.Now()); len(entries) > 0 {
+		t.Errorf("token %s falls into an issuer range: %+v", token, entries)
+	}
+
+	if again, err := v.Tokenize(cardvalidate.NewPAN(number), nil); err != nil || again != token {
+		t.Errorf("expected the same token, got %s, %v", again, err)
+	}
+
+	pan, err := v.Detokenize(token)
+	if err != nil || pan.Raw() != number {
+		t.Errorf("Detokenize = %s, %v", pan.Raw(), err)
+	}
+	if _, err := v.Detokenize("9999999999999995"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// The file is encrypted and can be reopened with the same key only.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte(number)) || bytes.Contains(data, []byte(token)) {
+		t.Error("vault file stores data in plain text")
+	}
+
+	reopened, err := Open(path, testKey, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if pan, err := reopened.Detokenize(token); err != nil || pan.Raw() != number {
+		t.Errorf("Detokenize after reopening = %s, %v", pan.Raw(), err)
+	}
+	if _, err := Open(path, bytes.Repeat([]byte{0x43}, KeySize), Options{}); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("expected ErrCorrupted with a wrong key, got %v", err)
+	}
+}
+
+func TestVaultOptions(t *testing.T) {
+	v, err := Open("", testKey, Options{KeepBIN: true, KeepLast4: true, AllowIssuerRanges: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	const number = "4539983514929271"
+	seen := make(map[string]bool)
+	for i := range 20 {
+		// Tokenize different card numbers with the same BIN and last 4 digits.
+		raw := []byte(number)
+		raw[6], raw[7] = '0'+byte(i/10), '0'+byte(i%10)
+		fixLuhn(raw, 11)
+
+		token, err := v.Tokenize(cardvalidate.NewPAN(string(raw)), nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if token[:6] != number[:6] || token[12:] != number[12:] || !luhn.Valid(token) {
+			t.Errorf("unexpected token for %s: %s", raw, token)
+		}
+		if seen[token] {
+			t.Errorf("duplicate token %s", token)
+		}
+		seen[token] = true
+	}
+	if v.Len() != 20 {
+		t.Errorf("expected 20 tokens, got %d", v.Len())
+	}
+}
+
+func TestVaultTenantRanges(t *testing.T) {
+	v, err := Open("", testKey, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// A tenant registry that leaves only IINs starting with 9 free.
+	reg, err := issuer.NewRegistry([]issuer.Entry{{
+		Issuer:   issuer.PrivateLabel,
+		Name:     "Acme Fleet",
+		Prefix:   issuer.NewIntRange(0, 8),
+		Length:   issuer.NewSingleIntRange(16),
+		Checksum: issuer.Luhn,
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for i := range 20 {
+		raw := []byte("4539983514929271")
+		raw[6], raw[7] = '0'+byte(i/10), '0'+byte(i%10)
+		fixLuhn(raw, 15)
+
+		token, err := v.Tokenize(cardvalidate.NewPAN(string(raw)), reg)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if token[0] != '9' {
+			t.Errorf("token %s falls into a tenant range", token)
+		}
+	}
+}
+
+func TestVaultTenantReuse(t *testing.T) {
+	v, err := Open("", testKey, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	pan := cardvalidate.NewPAN("4539983514929271")
+
+	first, err := v.Tokenize(pan, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// A tenant whose range covers the token tokenized without a tenant registry.
+	d := int(first[0] - '0')
+	reg, err := issuer.NewRegistry([]issuer.Entry{{
+		Issuer:   issuer.PrivateLabel,
+		Name:     "Acme Fleet",
+		Prefix:   issuer.NewIntRange(d, d),
+		Length:   issuer.NewSingleIntRange(16),
+		Checksum: issuer.Luhn,
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	second, err := v.Tokenize(pan, reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if second == first || second[0] == first[0] {
+		t.Errorf("token %s falls into a tenant range", second)
+	}
+	if token, _ := v.Tokenize(pan, reg); token != second {
+		t.Errorf("expected token %s to be reused, got %s", second, token)
+	}
+	if token, _ := v.Tokenize(pan, nil); token != first {
+		t.Errorf("expected token %s to be reused, got %s", first, token)
+	}
+	for _, token := range []string{first, second} {
+		if got, err := v.Detokenize(token); err != nil || got.Raw() != pan.Raw() {
+			t.Errorf("%s: unexpected card number %s, %v", token, got, err)
+		}
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	if _, err := Open("", []byte("short"), Options{}); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := Open("", testKey, Options{KeepBIN: true}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("expected ErrInvalidOptions, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "vault.bin")
+	if err := os.WriteFile(path, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, testKey, Options{}); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("expected ErrCorrupted, got %v", err)
+	}
+}
+
+func TestTokenizeInvalid(t *testing.T) {
+	v, _ := Open("", testKey, Options{KeepBIN: true, KeepLast4: true, AllowIssuerRanges: true})
+	for _, number := range []string{"", "41111", "4111x11111111111", "411111111111"} {
+		if _, err := v.Tokenize(cardvalidate.NewPAN(number), nil); !errors.Is(err, ErrInvalidPAN) {
+			t.Errorf("Tokenize(%q): expected ErrInvalidPAN, got %v", number, err)
+		}
+	}
+}